@@ -3,34 +3,64 @@ package cache
 import (
 	"fmt"
 	"go/types"
+	"log/slog"
 	"sort"
 	"strings"
-	"unicode"
 
 	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
 
-	"github.com/aarondl/strmangle"
 	"github.com/iancoleman/strcase"
 
 	"github.com/99designs/gqlgen/codegen/config"
 	gqlgenTemplates "github.com/99designs/gqlgen/codegen/templates"
-	"github.com/rs/zerolog/log"
 	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/logging"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/naming"
 )
 
 type BoilerCache struct {
 	BoilerModels []*structs.BoilerModel
 	BoilerEnums  []*structs.BoilerEnum
+	// Logger is the logger of the caches, the default logger of the generator when nil
+	Logger *slog.Logger
+	// Naming is the naming strategy of the schema, the model cache and the templates
+	Naming naming.Strategy
 }
 
-func InitializeBoilerCache(backend structs.Config) *BoilerCache {
-	log.Debug().Msg("[boiler-cache] building cache")
-	boilerModels, boilerEnums := GetBoilerModels(backend.Directory)
-	log.Debug().Msg("[boiler-cache] built cache!")
+// BoilerCacheConfig configures the boiler cache, the zero value uses the default logger and naming strategy
+type BoilerCacheConfig struct {
+	Logger *slog.Logger
+	// Naming is the naming strategy of the schema, the model cache and the templates. Its plurals are also used to
+	// find the plural table names of sqlboiler.
+	Naming naming.Strategy
+}
+
+func InitializeBoilerCache(backend structs.Config) (*BoilerCache, error) {
+	return InitializeBoilerCacheWithConfig(backend, BoilerCacheConfig{})
+}
+
+// InitializeBoilerCacheWithLogger reads the sqlboiler models and logs to the given logger instead of the default
+// logger of the generator, the model cache which is built from the boiler cache uses the same logger
+func InitializeBoilerCacheWithLogger(backend structs.Config, logger *slog.Logger) (*BoilerCache, error) {
+	return InitializeBoilerCacheWithConfig(backend, BoilerCacheConfig{Logger: logger})
+}
+
+// InitializeBoilerCacheWithConfig reads the sqlboiler models with the logger and naming strategy of the config, the
+// model cache which is built from the boiler cache uses the same logger and naming strategy
+func InitializeBoilerCacheWithConfig(backend structs.Config, cfg BoilerCacheConfig) (*BoilerCache, error) {
+	logger := logging.OrDefault(cfg.Logger)
+	logger.Debug("[boiler-cache] building cache")
+	boilerModels, boilerEnums, err := getBoilerModels(backend.Directory, logger, cfg.Naming)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("[boiler-cache] built cache!")
 	return &BoilerCache{
 		BoilerModels: boilerModels,
 		BoilerEnums:  boilerEnums,
-	}
+		Logger:       logger,
+		Naming:       cfg.Naming,
+	}, nil
 }
 
 type ModelCache struct {
@@ -41,6 +71,12 @@ type ModelCache struct {
 	Frontend   structs.Config
 	Output     structs.Config
 	Scalars    []string
+	// Schema is the GraphQL schema the models are read from
+	Schema *ast.Schema
+	// Logger is the logger of the boiler cache
+	Logger *slog.Logger
+	// Naming is the naming strategy of the boiler cache
+	Naming naming.Strategy
 }
 
 func copyConfig(cfg config.Config) *config.Config {
@@ -55,15 +91,17 @@ func InitializeModelCache(config *config.Config, boilerCache *BoilerCache, outpu
 	//}
 	//config := *originalConfig
 
-	log.Debug().Msg("[model-cache] get structs")
-	baseModels := getModelsFromSchema(config.Schema, boilerCache.BoilerModels)
+	logger := logging.OrDefault(boilerCache.Logger)
+	logger.Debug("[model-cache] get structs")
+	strategy := boilerCache.Naming
+	baseModels := getModelsFromSchema(config.Schema, boilerCache.BoilerModels, strategy, logger)
 
-	log.Debug().Msg("[model-cache] get extra's from schema")
-	interfaces, enums, scalars := getExtrasFromSchema(config.Schema, boilerCache.BoilerEnums, baseModels)
+	logger.Debug("[model-cache] get extra's from schema")
+	interfaces, enums, scalars := getExtrasFromSchema(config.Schema, boilerCache.BoilerEnums, baseModels, strategy)
 
-	log.Debug().Msg("[model-cache] enhance structs with information")
-	models := EnhanceModelsWithInformation(backend, enums, config, boilerCache.BoilerModels, baseModels, []string{frontend.PackageName, backend.PackageName, "boilergql"})
-	log.Debug().Msg("[model-cache] built cache!")
+	logger.Debug("[model-cache] enhance structs with information")
+	models := EnhanceModelsWithInformation(backend, enums, config, boilerCache.BoilerModels, baseModels, []string{frontend.PackageName, backend.PackageName, "boilergql"}, strategy, logger)
+	logger.Debug("[model-cache] built cache!")
 
 	return &ModelCache{
 		Models:     models,
@@ -73,6 +111,9 @@ func InitializeModelCache(config *config.Config, boilerCache *BoilerCache, outpu
 		Interfaces: interfaces,
 		Enums:      enumsWithout(enums, []string{"SortDirection", "Sort"}),
 		Scalars:    scalars,
+		Schema:     config.Schema,
+		Logger:     logger,
+		Naming:     strategy,
 	}
 }
 
@@ -82,14 +123,17 @@ func EnhanceModelsWithInformation(
 	cfg *config.Config,
 	boilerModels []*structs.BoilerModel,
 	models []*structs.Model,
-	ignoreTypePrefixes []string) []*structs.Model {
+	ignoreTypePrefixes []string,
+	strategy naming.Strategy,
+	logger *slog.Logger) []*structs.Model {
 	// always sort enums the same way to prevent merge conflicts in generated code
 	sort.Slice(enums, func(i, j int) bool {
 		return enums[i].Name < enums[j].Name
 	})
 
 	// Now we have all model's let enhance them with fields
-	enhanceModelsWithFields(enums, cfg.Schema, cfg, models, ignoreTypePrefixes)
+	enhanceModelsWithFields(enums, cfg.Schema, cfg, models, ignoreTypePrefixes, strategy, logging.OrDefault(logger))
+	enhanceSortEnumsWithBoilerFields(models)
 
 	// Add preload maps
 	enhanceModelsWithPreloadArray(backend, models)
@@ -104,7 +148,7 @@ func EnhanceModelsWithInformation(
 
 //nolint:gocognit,gocyclo
 func enhanceModelsWithFields(enums []*structs.Enum, schema *ast.Schema, cfg *config.Config,
-	models []*structs.Model, ignoreTypePrefixes []string) {
+	models []*structs.Model, ignoreTypePrefixes []string, strategy naming.Strategy, logger *slog.Logger) {
 	binder := cfg.NewBinder()
 
 	// getAstFieldType result depends only on field.Type.Name() — same unwrapped
@@ -137,7 +181,7 @@ func enhanceModelsWithFields(enums []*structs.Enum, schema *ast.Schema, cfg *con
 				err = astErrCache[typeName]
 			}
 			if err != nil {
-				log.Err(err).Msg("could not get field type from graphql schema")
+				logger.Error("could not get field type from graphql schema", "error", err)
 			}
 			jsonName := getGraphqlFieldName(cfg, m.Name, field)
 			name := gqlgenTemplates.ToGo(jsonName)
@@ -162,7 +206,13 @@ func enhanceModelsWithFields(enums []*structs.Enum, schema *ast.Schema, cfg *con
 			isPrimaryID := strings.EqualFold(name, "id")
 
 			// get sqlboiler information of the field
-			boilerField := findBoilerFieldOrForeignKey(m.BoilerModel, name, isObject)
+			boilerField := findBoilerFieldOrForeignKey(m.BoilerModel, name, isObject, strategy)
+			// a field which is kept after its column is renamed returns the value of the renamed column
+			if renamedTo := deprecatedRenamedTo(field); boilerField.Name == "" && renamedTo != "" {
+				boilerField = findBoilerFieldOrForeignKey(m.BoilerModel, gqlgenTemplates.ToGo(renamedTo), isObject, strategy)
+			}
+			// a field which is kept after its column is removed returns null
+			isDeprecated := field.Directives.ForName("deprecated") != nil
 			isString := strings.Contains(strings.ToLower(boilerField.Type), "string")
 			isNumberID := strings.HasSuffix(name, "ID") && !isString
 			isPrimaryNumberID := isPrimaryID && !isString
@@ -195,8 +245,9 @@ func enhanceModelsWithFields(enums []*structs.Enum, schema *ast.Schema, cfg *con
 
 				switch {
 				case m.IsPayload:
-				case IsPlural(name):
+				case strategy.IsPlural(name):
 				case ((m.IsFilter || m.IsWhere) && skipWarningInFilter) ||
+					isDeprecated ||
 					isEdges ||
 					isSort ||
 					isSortDirection ||
@@ -205,14 +256,16 @@ func enhanceModelsWithFields(enums []*structs.Enum, schema *ast.Schema, cfg *con
 					isNode:
 					// ignore
 				default:
-					log.Warn().Str("field", m.Name+"."+name).Msg("no database mapping")
+					logger.Warn("no database mapping", "field", m.Name+"."+name)
 				}
 			}
 
 			if boilerField.Name == "" {
 				if m.IsPayload || m.IsFilter || m.IsWhere || m.IsOrdering || m.IsEdge || isPageInfo || isEdges {
 				} else {
-					log.Warn().Str("field", m.Name+"."+name).Msg("no database mapping")
+					if !isDeprecated {
+						logger.Warn("no database mapping", "field", m.Name+"."+name)
+					}
 					continue
 				}
 			}
@@ -235,13 +288,13 @@ func enhanceModelsWithFields(enums []*structs.Enum, schema *ast.Schema, cfg *con
 				IsOr:          strings.EqualFold(name, "or"),
 				IsAnd:         strings.EqualFold(name, "and"),
 				IsWithDeleted: strings.EqualFold(name, "withDeleted"),
-				IsPlural:      IsPlural(name),
-				PluralName:    Plural(name),
+				IsPlural:      isPluralField(name, boilerField, strategy),
+				PluralName:    strategy.Plural(name),
 				OriginalType:  typ,
 				Description:   field.Description,
 				Enum:          enum,
 			}
-			field.ConvertConfig = getConvertConfig(enums, m, field)
+			field.ConvertConfig = getConvertConfig(enums, m, field, strategy)
 			m.Fields = append(m.Fields, field)
 		}
 	}
@@ -249,7 +302,34 @@ func enhanceModelsWithFields(enums []*structs.Enum, schema *ast.Schema, cfg *con
 	for _, m := range models {
 		for _, f := range m.Fields {
 			if f.BoilerField.Relationship != nil {
-				f.Relationship = findModel(models, f.BoilerField.Relationship.Name)
+				f.Relationship = findModel(models, strategy.TypeName(f.BoilerField.Relationship.Name))
+			}
+		}
+	}
+}
+
+// enhanceSortEnumsWithBoilerFields adds the sqlboiler field of every value of the {{Model}}Sort enums so sorting keeps
+// working when the naming strategy renames a field
+func enhanceSortEnumsWithBoilerFields(models []*structs.Model) {
+	for _, m := range models {
+		if !m.IsOrdering {
+			continue
+		}
+		model := findModel(models, strings.TrimSuffix(m.Name, "Ordering"))
+		for _, f := range m.Fields {
+			if f.Name != "Sort" || f.Enum == nil {
+				continue
+			}
+			for _, v := range f.Enum.Values {
+				v.BoilerFieldName = gqlgenTemplates.ToGo(v.Name)
+				if model == nil {
+					continue
+				}
+				for _, field := range model.Fields {
+					if strcase.ToScreamingSnake(field.JSONName) == v.Name && field.BoilerField.Name != "" {
+						v.BoilerFieldName = field.BoilerField.Name
+					}
+				}
 			}
 		}
 	}
@@ -335,33 +415,16 @@ func getGraphqlFieldName(cfg *config.Config, modelName string, field *ast.FieldD
 	return name
 }
 
-// TaskBlockedBies -> TaskBlockedBy
-// People -> Person
+// Singular returns the singular of a name with the default naming strategy e.g. TaskBlockedBies -> TaskBlockedBy,
+// People -> Person, use the Naming of the BoilerCache for the configured plurals
 func Singular(s string) string {
-	singular := strmangle.Singular(strcase.ToSnake(s))
-
-	singularTitle := strmangle.TitleCase(singular)
-	if IsFirstCharacterLowerCase(s) {
-		a := []rune(singularTitle)
-		a[0] = unicode.ToLower(a[0])
-		return string(a)
-	}
-	return singularTitle
+	return naming.Strategy{}.Singular(s)
 }
 
-// TaskBlockedBy -> TaskBlockedBies
-// Person -> Persons
-// Person -> People
+// Plural returns the plural of a name with the default naming strategy e.g. TaskBlockedBy -> TaskBlockedBies,
+// Person -> People, use the Naming of the BoilerCache for the configured plurals
 func Plural(s string) string {
-	plural := strmangle.Plural(strcase.ToSnake(s))
-
-	pluralTitle := strmangle.TitleCase(plural)
-	if IsFirstCharacterLowerCase(s) {
-		a := []rune(pluralTitle)
-		a[0] = unicode.ToLower(a[0])
-		return string(a)
-	}
-	return pluralTitle
+	return naming.Strategy{}.Plural(s)
 }
 
 func IsFirstCharacterLowerCase(s string) bool {
@@ -372,13 +435,22 @@ func IsFirstCharacterLowerCase(s string) bool {
 }
 
 func IsPlural(s string) bool {
-	return s == Plural(s)
+	return naming.Strategy{}.IsPlural(s)
 }
 
 func IsSingular(s string) bool {
 	return s == Singular(s)
 }
 
+// isPluralField returns true if the field is a list, a relationship of sqlboiler is a list when it is a slice so names
+// without plural or in another language are no problem
+func isPluralField(name string, boilerField structs.BoilerField, strategy naming.Strategy) bool {
+	if boilerField.IsRelation && !boilerField.InTable {
+		return boilerField.IsArray
+	}
+	return strategy.IsPlural(name)
+}
+
 func getShortType(longType string, ignoreTypePrefixes []string) string {
 	// longType e.g = gitlab.com/decicify/app/backend/graphql_models.FlowWhere
 	splittedBySlash := strings.Split(longType, "/")
@@ -423,7 +495,8 @@ func findModel(models []*structs.Model, search string) *structs.Model {
 //	return nil
 //}
 
-func findBoilerFieldOrForeignKey(boilerModel *structs.BoilerModel, golangGraphQLName string, isObject bool) structs.BoilerField {
+func findBoilerFieldOrForeignKey(boilerModel *structs.BoilerModel, golangGraphQLName string, isObject bool,
+	strategy naming.Strategy) structs.BoilerField {
 	if boilerModel == nil {
 		return structs.BoilerField{}
 	}
@@ -439,11 +512,30 @@ func findBoilerFieldOrForeignKey(boilerModel *structs.BoilerModel, golangGraphQL
 		if strings.EqualFold(field.Name, golangGraphQLName) {
 			return *field
 		}
+		if isRenamedBoilerField(boilerModel, field, golangGraphQLName, isObject, strategy) {
+			return *field
+		}
 	}
 	return structs.BoilerField{}
 }
 
-func getExtrasFromSchema(schema *ast.Schema, boilerEnums []*structs.BoilerEnum, models []*structs.Model) (interfaces []*structs.Interface, enums []*structs.Enum, scalars []string) {
+// isRenamedBoilerField returns true when the naming strategy renamed the field, or the relationship of the foreign key,
+// to the GraphQL field
+func isRenamedBoilerField(boilerModel *structs.BoilerModel, field *structs.BoilerField, golangGraphQLName string,
+	isObject bool, strategy naming.Strategy) bool {
+	renames := strategy.Renames
+	if isObject && field.IsRelation {
+		renamed := renames[boilerModel.Name+"."+field.RelationshipName]
+		if renamed != "" && strings.EqualFold(gqlgenTemplates.ToGo(renamed), golangGraphQLName) {
+			return true
+		}
+	}
+	renamed := renames[boilerModel.Name+"."+field.Name]
+	return renamed != "" && strings.EqualFold(gqlgenTemplates.ToGo(renamed), golangGraphQLName)
+}
+
+func getExtrasFromSchema(schema *ast.Schema, boilerEnums []*structs.BoilerEnum, models []*structs.Model,
+	strategy naming.Strategy) (interfaces []*structs.Interface, enums []*structs.Enum, scalars []string) {
 	for _, schemaType := range schema.Types {
 		switch schemaType.Kind {
 		case ast.Interface, ast.Union:
@@ -455,7 +547,7 @@ func getExtrasFromSchema(schema *ast.Schema, boilerEnums []*structs.BoilerEnum,
 			boilerEnum := findBoilerEnum(boilerEnums, schemaType.Name)
 			it := &structs.Enum{
 				Name:          schemaType.Name,
-				PluralName:    Plural(schemaType.Name),
+				PluralName:    strategy.Plural(schemaType.Name),
 				Description:   schemaType.Description,
 				HasBoilerEnum: boilerEnum != nil,
 				BoilerEnum:    boilerEnum,
@@ -466,7 +558,7 @@ func getExtrasFromSchema(schema *ast.Schema, boilerEnums []*structs.BoilerEnum,
 					Name:            v.Name,
 					NameLower:       strcase.ToLowerCamel(strings.ToLower(v.Name)),
 					Description:     v.Description,
-					BoilerEnumValue: findBoilerEnumValue(boilerEnum, v.Name),
+					BoilerEnumValue: findBoilerEnumValue(boilerEnum, v.Name, strategy),
 				})
 			}
 			if strings.HasPrefix(it.Name, "_") {
@@ -480,7 +572,8 @@ func getExtrasFromSchema(schema *ast.Schema, boilerEnums []*structs.BoilerEnum,
 	return
 }
 
-func getModelsFromSchema(schema *ast.Schema, boilerModels []*structs.BoilerModel) (models []*structs.Model) { //nolint:gocognit,gocyclo
+func getModelsFromSchema(schema *ast.Schema, boilerModels []*structs.BoilerModel, strategy naming.Strategy,
+	logger *slog.Logger) (models []*structs.Model) { //nolint:gocognit,gocyclo
 	for _, schemaType := range schema.Types {
 		// skip boiler plate from ggqlgen, we only want the structs
 		if strings.HasPrefix(schemaType.Name, "_") {
@@ -508,7 +601,7 @@ func getModelsFromSchema(schema *ast.Schema, boilerModels []*structs.BoilerModel
 				}
 
 				// We will try to find a corresponding boiler struct
-				boilerModel := FindBoilerModel(boilerModels, getBaseModelFromName(modelName))
+				boilerModel := findBoilerModelByTypeName(boilerModels, getBaseModelFromName(modelName), strategy)
 
 				isInput := doesEndWith(modelName, "Input")
 				isCreateInput := doesEndWith(modelName, "CreateInput")
@@ -543,7 +636,7 @@ func getModelsFromSchema(schema *ast.Schema, boilerModels []*structs.BoilerModel
 							// silent continue
 							continue
 						}
-						log.Debug().Str("model", modelName).Msg("skipped because no database model found")
+						logger.Debug("skipped because no database model found", "model", modelName)
 						continue
 					}
 				}
@@ -561,7 +654,7 @@ func getModelsFromSchema(schema *ast.Schema, boilerModels []*structs.BoilerModel
 					Name:                  modelName,
 					JSONName:              strcase.ToCamel(modelName),
 					Description:           schemaType.Description,
-					PluralName:            Plural(modelName),
+					PluralName:            strategy.Plural(modelName),
 					BoilerModel:           boilerModel,
 					HasBoilerModel:        hasBoilerModel,
 					IsInput:               isInput,
@@ -609,7 +702,8 @@ func getPreloadMapForModel(backend structs.Config, model *structs.Model) map[str
 		// } else {
 		// 	key = field.PluralName
 		// }
-		name := fmt.Sprintf("%v.%vRels.%v", backend.PackageName, model.Name, foreignKeyToRel(field.BoilerField.Name))
+		name := fmt.Sprintf("%v.%vRels.%v", backend.PackageName, model.BoilerModel.Name,
+			foreignKeyToRel(field.BoilerField.Name))
 		setting := structs.ColumnSetting{
 			Name:                  name,
 			IDAvailable:           !field.IsPlural,
@@ -690,16 +784,18 @@ func findBoilerEnum(enums []*structs.BoilerEnum, graphType string) *structs.Boil
 	return nil
 }
 
-func findBoilerEnumValue(enum *structs.BoilerEnum, name string) *structs.BoilerEnumValue {
+func findBoilerEnumValue(enum *structs.BoilerEnum, name string, strategy naming.Strategy) *structs.BoilerEnumValue {
 	if enum != nil {
 		for _, v := range enum.Values {
+			if strategy.EnumValueName(enum.Name, v.Name) == name {
+				return v
+			}
 			boilerName := strings.TrimPrefix(v.Name, enum.Name)
 			frontendName := strings.Replace(name, "_", "", -1)
 			if strings.EqualFold(boilerName, frontendName) {
 				return v
 			}
 		}
-		log.Error().Str(enum.Name, name).Msg("could not find sqlboiler enum value")
 	}
 
 	return nil
@@ -714,12 +810,18 @@ func findEnum(enums []*structs.Enum, graphType string) *structs.Enum {
 	return nil
 }
 
-func getConvertConfig(enums []*structs.Enum, model *structs.Model, field *structs.Field) (cc structs.ConvertConfig) { //nolint:gocognit,gocyclo
+func getConvertConfig(enums []*structs.Enum, model *structs.Model, field *structs.Field,
+	strategy naming.Strategy) (cc structs.ConvertConfig) { //nolint:gocognit,gocyclo
 	graphType := field.Type
 	boilType := field.BoilerField.Type
 
 	enum := findEnum(enums, field.TypeWithoutPointer)
-	if enum != nil { //nolint:nestif
+	if enum != nil && field.BoilerField.IsEnumArray {
+		// e.g. TypesStringArrayToUserRoles and UserRolesToTypesStringArray
+		cc.IsCustom = true
+		cc.ToBoiler = enum.PluralName + "To" + getBoilerTypeAsText(boilType)
+		cc.ToGraphQL = getBoilerTypeAsText(boilType) + "To" + enum.PluralName
+	} else if enum != nil { //nolint:nestif
 		cc.IsCustom = true
 		cc.ToBoiler = strings.TrimPrefix(
 			getToBoiler(
@@ -754,12 +856,26 @@ func getConvertConfig(enums []*structs.Enum, model *structs.Model, field *struct
 			if field.IsPrimaryID {
 				cc.ToGraphQL = model.Name + "IDToGraphQL(" + cc.ToGraphQL + ")"
 			} else if field.IsNumberID {
-				cc.ToGraphQL = field.BoilerField.Relationship.Name + "IDToGraphQL(" + cc.ToGraphQL + ")"
+				cc.ToGraphQL = strategy.TypeName(field.BoilerField.Relationship.Name) + "IDToGraphQL(" + cc.ToGraphQL + ")"
 			}
 
 			isInt := strings.HasPrefix(strings.ToLower(boilType), "int") && !strings.HasPrefix(strings.ToLower(boilType), "uint")
 
-			if strings.HasPrefix(boilType, "null") {
+			// the generated {{Model}}ID decoders use the GlobalIDCodec and ignore ids of other models
+			decoder := ""
+			if field.IsPrimaryID && model.BoilerModel != nil {
+				decoder = strategy.TypeName(model.BoilerModel.Name) + "ID"
+			} else if field.IsNumberID && field.BoilerField.Relationship != nil {
+				decoder = strategy.TypeName(field.BoilerField.Relationship.Name) + "ID"
+			}
+
+			if decoder != "" && strings.HasPrefix(boilType, "null.") {
+				kind := strings.TrimPrefix(boilType, "null.")
+				cc.ToBoiler = fmt.Sprintf("null.New%v(%v(%v(%v)), %v != \"\")",
+					kind, strings.ToLower(kind), decoder, cc.ToBoiler, cc.ToBoiler)
+			} else if decoder != "" {
+				cc.ToBoiler = fmt.Sprintf("%v(%v(%v))", boilType, decoder, cc.ToBoiler)
+			} else if strings.HasPrefix(boilType, "null") {
 				cc.ToBoiler = fmt.Sprintf("boilergql.IDToNullBoiler(%v)", cc.ToBoiler)
 				if isInt {
 					cc.ToBoiler = fmt.Sprintf("boilergql.NullUintToNullInt(%v)", cc.ToBoiler)
@@ -839,3 +955,13 @@ func FindBoilerModel(models []*structs.BoilerModel, modelName string) *structs.B
 	}
 	return nil
 }
+
+// findBoilerModelByTypeName returns the boiler model of which the GraphQL type has the name of the naming strategy
+func findBoilerModelByTypeName(models []*structs.BoilerModel, typeName string, strategy naming.Strategy) *structs.BoilerModel {
+	for _, m := range models {
+		if strings.EqualFold(strategy.TypeName(m.Name), typeName) {
+			return m
+		}
+	}
+	return nil
+}
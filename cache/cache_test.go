@@ -2,6 +2,8 @@ package cache
 
 import (
 	"testing"
+
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/naming"
 )
 
 func TestShortType(t *testing.T) {
@@ -20,3 +22,21 @@ func testShortType(t *testing.T, input, output string) {
 		t.Errorf("%v should result in %v but did result in %v", input, output, result)
 	}
 }
+
+func TestFindTableName(t *testing.T) {
+	strategy := naming.Strategy{Plurals: map[string]string{"huis": "huizen"}}
+
+	tableNames := []string{"Users", "Huizen", "News", "Account"}
+	tests := map[string]string{
+		"User":    "Users",
+		"Huis":    "Huizen",
+		"News":    "News",
+		"Account": "Account",
+		"Unknown": "Unknown",
+	}
+	for modelName, expected := range tests {
+		if got := findTableName(tableNames, modelName, strategy); got != expected {
+			t.Errorf("findTableName(%v) = %v, want %v", modelName, got, expected)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// deprecationDateLayout is the layout of the date in the reason of fields which are kept after their column is removed
+const deprecationDateLayout = "2006-01-02"
+
+var (
+	deprecationDateRegex    = regexp.MustCompile(` on (\d{4}-\d{2}-\d{2})$`) //nolint:gochecknoglobals
+	deprecationRenamedRegex = regexp.MustCompile(`^Use (\w+) instead`)       //nolint:gochecknoglobals
+)
+
+// DeprecationReasonRemoved is the reason of a field which is kept after its column is removed from the database
+func DeprecationReasonRemoved(removedAt time.Time) string {
+	return "Removed from the database on " + removedAt.Format(deprecationDateLayout)
+}
+
+// DeprecationReasonRenamed is the reason of a field which is kept after its column is renamed in the database, the
+// converts map the field to the renamed column
+func DeprecationReasonRenamed(field string, renamedAt time.Time) string {
+	return fmt.Sprintf("Use %v instead, renamed in the database on %v", field, renamedAt.Format(deprecationDateLayout))
+}
+
+// DeprecatedSince returns the date in the reason of a field which is kept after its column is removed or renamed
+func DeprecatedSince(field *ast.FieldDefinition) (time.Time, bool) {
+	match := deprecationDateRegex.FindStringSubmatch(deprecationReason(field))
+	if match == nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(deprecationDateLayout, match[1])
+	return t, err == nil
+}
+
+// deprecatedRenamedTo returns the field of which the column is the renamed column of a deprecated field
+func deprecatedRenamedTo(field *ast.FieldDefinition) string {
+	if match := deprecationRenamedRegex.FindStringSubmatch(deprecationReason(field)); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+func deprecationReason(field *ast.FieldDefinition) string {
+	directive := field.Directives.ForName("deprecated")
+	if directive == nil {
+		return ""
+	}
+	reason := directive.Arguments.ForName("reason")
+	if reason == nil || reason.Value == nil {
+		return ""
+	}
+	return reason.Value.Raw
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
+)
+
+// EnumMapping adds an enum which sqlboiler does not generate e.g. for a plain string or int column or for an array
+// column of a Postgres enum. The enum is added to the generated schema and gets the same converters and filters as the
+// enums of sqlboiler.
+type EnumMapping struct {
+	// Name of the enum in GraphQL e.g. UserRole
+	Name string
+	// Columns which hold the enum as Model.Field e.g. User.Role, the columns are string, int, types.StringArray or
+	// types.Int64Array columns and can not mix string and int values
+	Columns []string
+	// Values in the order of the GraphQL enum
+	Values []EnumMappingValue
+}
+
+type EnumMappingValue struct {
+	// GraphQL is the value in the schema e.g. SUPER_ADMIN
+	GraphQL string
+	// Database is the value in the column e.g. super_admin or 1 for an int column
+	Database string
+}
+
+var enumColumnTypes = map[string]string{ //nolint:gochecknoglobals
+	"string":            "string",
+	"null.String":       "string",
+	"types.StringArray": "string",
+	"int":               "int",
+	"null.Int":          "int",
+	"types.Int64Array":  "int",
+}
+
+// AddEnumMapping adds an enum which is not generated by sqlboiler and uses it for the columns of the mapping
+func (c *BoilerCache) AddEnumMapping(mapping EnumMapping) error {
+	if mapping.Name == "" {
+		return fmt.Errorf("enum mapping without name")
+	}
+	for _, enum := range c.BoilerEnums {
+		if enum.Name == mapping.Name {
+			return fmt.Errorf("enum mapping %v: an enum with the same name already exists", mapping.Name)
+		}
+	}
+	if len(mapping.Values) == 0 {
+		return fmt.Errorf("enum mapping %v: no values", mapping.Name)
+	}
+
+	enum := &structs.BoilerEnum{
+		Name:      mapping.Name,
+		IsMapping: true,
+	}
+	var fields []*structs.BoilerField
+	for _, column := range mapping.Columns {
+		modelName, fieldName, _ := strings.Cut(column, ".")
+		model := FindBoilerModel(c.BoilerModels, modelName)
+		if model == nil {
+			return fmt.Errorf("enum mapping %v: model of column %v not found", mapping.Name, column)
+		}
+		field := findBoilerField(model.Fields, fieldName)
+		if field == nil || !field.InTable {
+			return fmt.Errorf("enum mapping %v: column %v not found", mapping.Name, column)
+		}
+		databaseType, ok := enumColumnTypes[field.Type]
+		if !ok {
+			return fmt.Errorf("enum mapping %v: column %v has type %v which can not hold an enum",
+				mapping.Name, column, field.Type)
+		}
+		if enum.DatabaseType != "" && enum.DatabaseType != databaseType {
+			return fmt.Errorf("enum mapping %v: column %v holds %v values but the other columns hold %v values",
+				mapping.Name, column, databaseType, enum.DatabaseType)
+		}
+		enum.DatabaseType = databaseType
+		isArray := strings.HasSuffix(field.Type, "Array")
+		enum.Fields = append(enum.Fields, &structs.BoilerEnumField{
+			ModelName: model.Name,
+			FieldKey:  field.Name,
+			IsArray:   isArray,
+		})
+		fields = append(fields, field)
+	}
+	if enum.DatabaseType == "" {
+		return fmt.Errorf("enum mapping %v: no columns", mapping.Name)
+	}
+
+	seen := map[string]bool{}
+	for _, value := range mapping.Values {
+		if value.GraphQL == "" {
+			return fmt.Errorf("enum mapping %v: value without GraphQL name", mapping.Name)
+		}
+		if seen[value.GraphQL] {
+			return fmt.Errorf("enum mapping %v: value %v is mapped twice", mapping.Name, value.GraphQL)
+		}
+		seen[value.GraphQL] = true
+
+		literal := strconv.Quote(value.Database)
+		if enum.DatabaseType == "int" {
+			if _, err := strconv.Atoi(value.Database); err != nil {
+				return fmt.Errorf("enum mapping %v: value %v is mapped to %q which is not an int",
+					mapping.Name, value.GraphQL, value.Database)
+			}
+			literal = value.Database
+		}
+		enum.Values = append(enum.Values, &structs.BoilerEnumValue{
+			// the same name sqlboiler would generate so the values are found like the values of sqlboiler enums
+			Name:    mapping.Name + strcase.ToCamel(strings.ToLower(value.GraphQL)),
+			Literal: literal,
+		})
+	}
+
+	enum.ModelName = enum.Fields[0].ModelName
+	enum.ModelFieldKey = enum.Fields[0].FieldKey
+	for i, field := range fields {
+		field.IsEnum = true
+		field.IsEnumArray = enum.Fields[i].IsArray
+		field.IsRelation = false
+		field.Enum = *enum
+	}
+	for _, model := range c.BoilerModels {
+		for _, enumField := range enum.Fields {
+			if enumField.ModelName == model.Name {
+				model.Enums = append(model.Enums, enum)
+				break
+			}
+		}
+	}
+	c.BoilerEnums = append(c.BoilerEnums, enum)
+	return nil
+}
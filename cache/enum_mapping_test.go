@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
+)
+
+func TestAddEnumMapping(t *testing.T) {
+	newBoilerCache := func() *BoilerCache {
+		return &BoilerCache{BoilerModels: []*structs.BoilerModel{{
+			Name: "User",
+			Fields: []*structs.BoilerField{
+				{Name: "Role", Type: "string", InTable: true},
+				{Name: "Priority", Type: "null.Int", InTable: true},
+				{Name: "Permissions", Type: "types.StringArray", InTable: true},
+				{Name: "CreatedAt", Type: "time.Time", InTable: true},
+			},
+		}}}
+	}
+	values := []EnumMappingValue{{GraphQL: "SUPER_ADMIN", Database: "super_admin"}, {GraphQL: "VIEWER", Database: "viewer"}}
+
+	tests := []struct {
+		name    string
+		mapping EnumMapping
+		literal string
+		err     string
+	}{
+		{
+			name:    "string and array column",
+			mapping: EnumMapping{Name: "UserRole", Columns: []string{"User.Role", "User.Permissions"}, Values: values},
+			literal: `"super_admin"`,
+		},
+		{
+			name: "int column",
+			mapping: EnumMapping{Name: "Priority", Columns: []string{"User.Priority"},
+				Values: []EnumMappingValue{{GraphQL: "HIGH", Database: "1"}}},
+			literal: "1",
+		},
+		{
+			name:    "unknown column",
+			mapping: EnumMapping{Name: "UserRole", Columns: []string{"User.Unknown"}, Values: values},
+			err:     "column User.Unknown not found",
+		},
+		{
+			name:    "column which can not hold an enum",
+			mapping: EnumMapping{Name: "UserRole", Columns: []string{"User.CreatedAt"}, Values: values},
+			err:     "can not hold an enum",
+		},
+		{
+			name:    "string and int columns",
+			mapping: EnumMapping{Name: "UserRole", Columns: []string{"User.Role", "User.Priority"}, Values: values},
+			err:     "holds int values but the other columns hold string values",
+		},
+		{
+			name:    "value which is not an int",
+			mapping: EnumMapping{Name: "Priority", Columns: []string{"User.Priority"}, Values: values},
+			err:     "is not an int",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			c := newBoilerCache()
+			err := c.AddEnumMapping(tt.mapping)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, column := range tt.mapping.Columns {
+				field := findBoilerField(c.BoilerModels[0].Fields, strings.TrimPrefix(column, "User."))
+				if !field.IsEnum || field.Enum.Name != tt.mapping.Name {
+					t.Errorf("%v is not an enum column of %v", column, tt.mapping.Name)
+				}
+				if field.IsEnumArray != strings.HasSuffix(field.Type, "Array") {
+					t.Errorf("%v: IsEnumArray = %v", column, field.IsEnumArray)
+				}
+			}
+			if literal := c.BoilerEnums[0].Values[0].Literal; literal != tt.literal {
+				t.Errorf("got literal %v, want %v", literal, tt.literal)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aarondl/strmangle"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/logging"
+)
+
+// ColumnInfo contains the constraints of a column which can not be derived from the sqlboiler structs
+type ColumnInfo struct {
+	MaxLength int
+	Precision int
+	Scale     int
+	IsUnique  bool
+	Comment   string
+}
+
+var (
+	createTableRegex = regexp.MustCompile(`(?is)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s(]+)\s*\((.*?)\n\s*\)[^;]*;`)                                  //nolint:gochecknoglobals,lll
+	columnTypeRegex  = regexp.MustCompile(`(?i)^(?:varchar|character\s+varying|char|character|varbinary|decimal|numeric)\s*\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)`) //nolint:gochecknoglobals,lll
+	uniqueKeyRegex   = regexp.MustCompile(`(?i)UNIQUE\s+(?:KEY\s+|INDEX\s+)?(?:[^\s(]+\s*)?\(([^)]*)\)`)                                                      //nolint:gochecknoglobals,lll
+	alterUniqueRegex = regexp.MustCompile(`(?is)ALTER\s+TABLE\s+(?:ONLY\s+)?([^\s]+)\s+ADD\s+CONSTRAINT\s+[^\s]+\s+UNIQUE\s*\(([^)]*)\)`)                     //nolint:gochecknoglobals,lll
+	// COMMENT ON COLUMN public.users.email IS 'Primary email'; in a pg_dump
+	commentOnRegex = regexp.MustCompile(`(?is)COMMENT\s+ON\s+(TABLE|VIEW|MATERIALIZED\s+VIEW|COLUMN|TYPE)\s+([^\s]+)\s+IS\s+'((?:[^'\\]|''|\\.)*)'\s*;`) //nolint:gochecknoglobals,lll
+	// email varchar(255) COMMENT 'Primary email' and ) ENGINE=InnoDB COMMENT='Users'; in a mysqldump
+	inlineCommentRegex = regexp.MustCompile(`(?i)\sCOMMENT\s*=?\s*'((?:[^'\\]|''|\\.)*)'`) //nolint:gochecknoglobals
+)
+
+var sqlStringReplacer = strings.NewReplacer(`''`, `'`, `\'`, `'`, `\\`, `\`, `\n`, "\n") //nolint:gochecknoglobals
+
+// AddSchemaDump enriches the boiler fields with the constraints of a database schema dump e.g. the output of
+// pg_dump --schema-only or mysqldump --no-data. These are used to generate input validation.
+func (c *BoilerCache) AddSchemaDump(file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("could not read schema dump: %w", err)
+	}
+	tables := parseSchemaDump(string(content))
+	tableComments, typeComments := parseSchemaDumpComments(string(content))
+
+	for _, model := range c.BoilerModels {
+		if comment := tableComments[model.TableName]; comment != "" {
+			model.Description = comment
+		}
+		columns, ok := tables[model.TableName]
+		if !ok {
+			logging.OrDefault(c.Logger).Debug("table not found in schema dump", "table", model.TableName)
+			continue
+		}
+		for _, field := range model.Fields {
+			column, ok := columns[field.Name]
+			if !ok || !field.InTable {
+				continue
+			}
+			field.MaxLength = column.MaxLength
+			field.Precision = column.Precision
+			field.Scale = column.Scale
+			field.IsUnique = column.IsUnique
+			if column.Comment != "" {
+				field.Description = column.Comment
+			}
+		}
+	}
+	for _, enum := range c.BoilerEnums {
+		if comment := typeComments[enum.Name]; comment != "" {
+			enum.Description = comment
+		}
+	}
+	return nil
+}
+
+// parseSchemaDump returns the columns per table, tables and columns are keyed by the name sqlboiler uses
+// in the generated structs e.g. users.first_name -> Users.FirstName
+func parseSchemaDump(content string) map[string]map[string]*ColumnInfo {
+	tables := map[string]map[string]*ColumnInfo{}
+
+	for _, match := range createTableRegex.FindAllStringSubmatch(content, -1) {
+		columns := map[string]*ColumnInfo{}
+		tables[schemaDumpName(match[1])] = columns
+
+		for _, line := range strings.Split(match[2], "\n") {
+			line = strings.TrimSuffix(strings.TrimSpace(line), ",")
+			if line == "" || strings.HasPrefix(line, "--") {
+				continue
+			}
+			upperLine := strings.ToUpper(line)
+			if isTableConstraint(upperLine) {
+				if unique := uniqueKeyRegex.FindStringSubmatch(line); unique != nil {
+					markUniqueColumns(columns, unique[1])
+				}
+				continue
+			}
+
+			var comment string
+			if loc := inlineCommentRegex.FindStringSubmatchIndex(line); loc != nil {
+				comment = sqlStringReplacer.Replace(line[loc[2]:loc[3]])
+				line = line[:loc[0]] + line[loc[1]:]
+				upperLine = strings.ToUpper(line)
+			}
+			parts := strings.SplitN(line, " ", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			column := &ColumnInfo{
+				IsUnique: strings.Contains(upperLine, " UNIQUE"),
+				Comment:  comment,
+			}
+			if typeMatch := columnTypeRegex.FindStringSubmatch(strings.TrimSpace(parts[1])); typeMatch != nil {
+				size, _ := strconv.Atoi(typeMatch[1])
+				if typeMatch[2] != "" || isNumericType(parts[1]) {
+					column.Precision = size
+					column.Scale, _ = strconv.Atoi(typeMatch[2])
+				} else {
+					column.MaxLength = size
+				}
+			}
+			columns[schemaDumpName(parts[0])] = column
+		}
+	}
+
+	for _, match := range alterUniqueRegex.FindAllStringSubmatch(content, -1) {
+		if columns, ok := tables[schemaDumpName(match[1])]; ok {
+			markUniqueColumns(columns, match[2])
+		}
+	}
+	for _, match := range commentOnRegex.FindAllStringSubmatch(content, -1) {
+		if !strings.EqualFold(match[1], "COLUMN") {
+			continue
+		}
+		i := strings.LastIndex(match[2], ".")
+		if i < 0 {
+			continue
+		}
+		if column, ok := tables[schemaDumpName(match[2][:i])][schemaDumpName(match[2][i+1:])]; ok {
+			column.Comment = sqlStringReplacer.Replace(match[3])
+		}
+	}
+	return tables
+}
+
+// parseSchemaDumpComments returns the comments of tables and views by the name sqlboiler uses for the model and the
+// comments of types e.g. enums by the name sqlboiler uses for the enum
+func parseSchemaDumpComments(content string) (map[string]string, map[string]string) {
+	tableComments := map[string]string{}
+	typeComments := map[string]string{}
+
+	for _, match := range createTableRegex.FindAllStringSubmatch(content, -1) {
+		// table options of MySQL are written after the last closing parenthesis
+		options := match[0][strings.LastIndex(match[0], "\n"):]
+		if comment := inlineCommentRegex.FindStringSubmatch(options); comment != nil {
+			tableComments[schemaDumpName(match[1])] = sqlStringReplacer.Replace(comment[1])
+		}
+	}
+	for _, match := range commentOnRegex.FindAllStringSubmatch(content, -1) {
+		comment := sqlStringReplacer.Replace(match[3])
+		switch strings.ToUpper(match[1]) {
+		case "COLUMN":
+			// column comments are parsed with the columns
+		case "TYPE":
+			typeComments[schemaDumpName(match[2])] = comment
+		default:
+			tableComments[schemaDumpName(match[2])] = comment
+		}
+	}
+	return tableComments, typeComments
+}
+
+func isTableConstraint(upperLine string) bool {
+	for _, prefix := range []string{"CONSTRAINT", "PRIMARY", "KEY", "UNIQUE", "INDEX", "FOREIGN", "CHECK", "FULLTEXT"} {
+		if strings.HasPrefix(upperLine, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumericType(v string) bool {
+	v = strings.ToLower(strings.TrimSpace(v))
+	return strings.HasPrefix(v, "decimal") || strings.HasPrefix(v, "numeric")
+}
+
+func markUniqueColumns(columns map[string]*ColumnInfo, columnList string) {
+	names := strings.Split(columnList, ",")
+	// only single column unique constraints make a column unique
+	if len(names) != 1 {
+		return
+	}
+	if column, ok := columns[schemaDumpName(names[0])]; ok {
+		column.IsUnique = true
+	}
+}
+
+// schemaDumpName strips quotes and schema's from a name and returns it like sqlboiler names it in go
+func schemaDumpName(v string) string {
+	v = strings.TrimSpace(v)
+	if i := strings.LastIndex(v, "."); i >= 0 {
+		v = v[i+1:]
+	}
+	v = strings.Trim(v, "`\"[]")
+	return strmangle.TitleCase(v)
+}
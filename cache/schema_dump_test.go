@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"testing"
+)
+
+const postgresDump = `
+CREATE TABLE public.user_profile (
+    id integer NOT NULL,
+    email character varying(255) NOT NULL,
+    nickname character varying(50) UNIQUE,
+    balance numeric(10,2) DEFAULT 0 NOT NULL,
+    bio text
+);
+
+ALTER TABLE ONLY public.user_profile
+    ADD CONSTRAINT user_profile_email_key UNIQUE (email);
+
+COMMENT ON TABLE public.user_profile IS 'Profiles of the users';
+COMMENT ON COLUMN public.user_profile.bio IS 'Shown on the profile, it''s unique';
+COMMENT ON TYPE public.user_role IS 'Role of a user';
+`
+
+const mysqlDump = "CREATE TABLE `user_profile` (\n" +
+	"  `id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `email` varchar(255) NOT NULL,\n" +
+	"  `balance` decimal(10,2) NOT NULL DEFAULT '0.00',\n" +
+	"  `bio` text COMMENT 'Shown on the profile, it''s unique',\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  UNIQUE KEY `user_profile_email` (`email`)\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Profiles of the users';\n"
+
+func TestParseSchemaDump(t *testing.T) {
+	for name, dump := range map[string]string{"postgres": postgresDump, "mysql": mysqlDump} {
+		tables := parseSchemaDump(dump)
+		columns, ok := tables["UserProfile"]
+		if !ok {
+			t.Fatalf("%v: table UserProfile not found in %v", name, tables)
+		}
+		testColumnInfo(t, name, columns["Email"], ColumnInfo{MaxLength: 255, IsUnique: true})
+		testColumnInfo(t, name, columns["Balance"], ColumnInfo{Precision: 10, Scale: 2})
+		testColumnInfo(t, name, columns["ID"], ColumnInfo{})
+		testColumnInfo(t, name, columns["Bio"], ColumnInfo{Comment: "Shown on the profile, it's unique"})
+
+		tableComments, _ := parseSchemaDumpComments(dump)
+		if got := tableComments["UserProfile"]; got != "Profiles of the users" {
+			t.Errorf("%v: got table comment %q", name, got)
+		}
+	}
+	if _, typeComments := parseSchemaDumpComments(postgresDump); typeComments["UserRole"] != "Role of a user" {
+		t.Errorf("postgres: got type comments %v", typeComments)
+	}
+	testColumnInfo(t, "postgres", parseSchemaDump(postgresDump)["UserProfile"]["Nickname"],
+		ColumnInfo{MaxLength: 50, IsUnique: true})
+}
+
+func testColumnInfo(t *testing.T, name string, column *ColumnInfo, want ColumnInfo) {
+	if column == nil {
+		t.Errorf("%v: column not found, want %+v", name, want)
+		return
+	}
+	if *column != want {
+		t.Errorf("%v: got %+v, want %+v", name, *column, want)
+	}
+}
@@ -0,0 +1,534 @@
+package cache
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aarondl/strmangle"
+	"github.com/iancoleman/strcase"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
+	"golang.org/x/tools/go/packages"
+)
+
+const modelsPackageLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+	packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
+
+// modelsPackage is the type checked package of the sqlboiler models. Type errors are tolerated e.g. when a
+// dependency of the models is not downloaded yet, the type of a field is then read from its source.
+type modelsPackage struct {
+	pkg *packages.Package
+	// fieldTypes are the type expressions of the struct fields in the models package by the position of the field
+	fieldTypes map[token.Pos]ast.Expr
+	// fieldDocs are the comments of the struct fields in the models package by the position of the field
+	fieldDocs map[token.Pos]string
+	// typeDocs are the comments of the types in the models package by type name
+	typeDocs map[string]string
+	logger   *slog.Logger
+}
+
+func loadModelsPackage(dir string, logger *slog.Logger) (*modelsPackage, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	// the go.mod of the project should never be changed by the generator, missing modules result in type errors
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:       modelsPackageLoadMode,
+		Dir:        dir,
+		BuildFlags: []string{"-mod=readonly"},
+	}, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 || pkgs[0].Types == nil || pkgs[0].TypesInfo == nil {
+		return nil, fmt.Errorf("could not find a go package in %v", dir)
+	}
+	pkg := pkgs[0]
+	for _, pkgErr := range pkg.Errors {
+		logger.Debug("sqlboiler models contain an error, unresolved types are read from source",
+			"error", pkgErr)
+	}
+
+	p := &modelsPackage{
+		pkg:        pkg,
+		fieldTypes: map[token.Pos]ast.Expr{},
+		fieldDocs:  map[token.Pos]string{},
+		typeDocs:   map[string]string{},
+		logger:     logger,
+	}
+	for _, file := range pkg.Syntax {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.GenDecl:
+				if n.Tok != token.TYPE {
+					break
+				}
+				for _, spec := range n.Specs {
+					typeSpec := spec.(*ast.TypeSpec) //nolint:forcetypeassert
+					doc := typeSpec.Doc
+					// a single type is documented on the declaration
+					if doc == nil && len(n.Specs) == 1 {
+						doc = n.Doc
+					}
+					p.typeDocs[typeSpec.Name.Name] = commentText(doc)
+				}
+			case *ast.Field:
+				for _, name := range n.Names {
+					p.fieldTypes[name.Pos()] = n.Type
+					p.fieldDocs[name.Pos()] = commentText(n.Doc, n.Comment)
+				}
+			}
+			return true
+		})
+	}
+	return p, nil
+}
+
+// parseStructFields returns the type of every field of every struct in the models package by StructName.key
+// e.g.
+// Address.ID: null.Integer
+// Address.Longitude: null.String
+// Address.Latitude : null.Decimal
+// needed to generate the right convert code. The fields of embedded structs are added to the struct which embeds them.
+func (p *modelsPackage) parseStructFields() (map[string]string, map[string]int) {
+	fieldsMap := make(map[string]string)
+	fieldsOrder := make(map[string]int)
+
+	scope := p.pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			continue
+		}
+		structType, ok := typeName.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		for i, field := range p.structFields(name, structType) {
+			k := name + "." + field.Name()
+			fieldsMap[k] = p.fieldType(field.Var)
+			fieldsOrder[k] = i
+		}
+	}
+	return fieldsMap, fieldsOrder
+}
+
+// modelDescriptionRegex matches the comment sqlboiler writes above every model which does not describe the table
+var modelDescriptionRegex = regexp.MustCompile(`^\w+ is an object representing the database (table|view)\.$`) //nolint:gochecknoglobals,lll
+
+// parseDescriptions returns the comments of the structs and their fields in the models package by StructName and
+// StructName.key, sqlboiler writes the comments of tables and columns above them. Structs and fields without comment are
+// left out.
+func (p *modelsPackage) parseDescriptions() map[string]string {
+	descriptions := map[string]string{}
+	scope := p.pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			continue
+		}
+		structType, ok := typeName.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		var lines []string
+		for _, line := range strings.Split(p.typeDocs[name], "\n") {
+			if !modelDescriptionRegex.MatchString(line) {
+				lines = append(lines, line)
+			}
+		}
+		if description := strings.TrimSpace(strings.Join(lines, "\n")); description != "" {
+			descriptions[name] = description
+		}
+		for _, field := range p.structFields(name, structType) {
+			if description := p.fieldDocs[field.Pos()]; description != "" {
+				descriptions[name+"."+field.Name()] = description
+			}
+		}
+	}
+	return descriptions
+}
+
+// commentText returns the text of comments without comment markers and surrounding whitespace
+func commentText(groups ...*ast.CommentGroup) string {
+	var a []string
+	for _, group := range groups {
+		if text := strings.TrimSpace(group.Text()); text != "" {
+			a = append(a, text)
+		}
+	}
+	return strings.Join(a, "\n")
+}
+
+// structField is a field of a struct or a field which is promoted from an embedded struct
+type structField struct {
+	*types.Var
+	tag   string
+	depth int
+}
+
+// structFields returns the fields of a struct in order with the fields of embedded structs in place of the embedded
+// struct, a promoted field is left out when the struct has a field with the same name like Go does
+func (p *modelsPackage) structFields(structName string, structType *types.Struct) []structField {
+	fields := p.appendStructFields(nil, structName, structType, 0, map[*types.Struct]bool{})
+
+	shallowest := map[string]int{}
+	for _, field := range fields {
+		if depth, ok := shallowest[field.Name()]; !ok || field.depth < depth {
+			shallowest[field.Name()] = field.depth
+		}
+	}
+	a := fields[:0]
+	for _, field := range fields {
+		if shallowest[field.Name()] == field.depth {
+			a = append(a, field)
+			// a name could be promoted twice at the same depth
+			shallowest[field.Name()] = -1
+		}
+	}
+	return a
+}
+
+func (p *modelsPackage) appendStructFields(
+	fields []structField,
+	structName string,
+	structType *types.Struct,
+	depth int,
+	seen map[*types.Struct]bool,
+) []structField {
+	// embedding is cyclic when done through pointers
+	if seen[structType] {
+		return fields
+	}
+	seen[structType] = true
+
+	for f := 0; f < structType.NumFields(); f++ {
+		field := structType.Field(f)
+		if field.Pkg() != p.pkg.Types && !field.Exported() {
+			continue
+		}
+		if !field.Embedded() {
+			fields = append(fields, structField{Var: field, tag: structType.Tag(f), depth: depth})
+			continue
+		}
+		embedded := field.Type()
+		if pointer, ok := embedded.(*types.Pointer); ok {
+			embedded = pointer.Elem()
+		}
+		if embeddedStruct, ok := embedded.Underlying().(*types.Struct); ok {
+			fields = p.appendStructFields(fields, structName, embeddedStruct, depth+1, seen)
+		} else {
+			p.logger.Debug("ignoring embedded field which is not a struct",
+				"struct", structName, "field", field.Name())
+		}
+	}
+	return fields
+}
+
+// fieldType returns the type of a field like it is written in the models package e.g. null.String, relationships to
+// other models are returned without pointer (*Organization -> Organization) and slices of models with a Slice suffix.
+func (p *modelsPackage) fieldType(field *types.Var) string {
+	if expr, ok := p.fieldTypes[field.Pos()]; ok && field.Pkg() == p.pkg.Types {
+		return p.exprType(expr)
+	}
+	return p.typeType(field.Type())
+}
+
+func (p *modelsPackage) exprType(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.ParenExpr:
+		return p.exprType(e.X)
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			return p.packageName(x) + "." + e.Sel.Name
+		}
+	case *ast.StarExpr:
+		if p.isModelsType(e.X) {
+			return p.exprType(e.X)
+		}
+		return "*" + p.exprType(e.X)
+	case *ast.ArrayType:
+		if e.Len == nil {
+			if star, ok := e.Elt.(*ast.StarExpr); ok && p.isModelsType(star.X) {
+				return p.exprType(star.X) + "Slice"
+			}
+			return p.exprType(e.Elt) + "Slice"
+		}
+	}
+	// maps, funcs, channels and other types which can not be converted are kept as written
+	return types.ExprString(expr)
+}
+
+// packageName returns the name of an imported package instead of the name under which it is imported, so renamed
+// imports are handled the same e.g. nullv8.String -> null.String
+func (p *modelsPackage) packageName(x *ast.Ident) string {
+	pkgName, ok := p.pkg.TypesInfo.Uses[x].(*types.PkgName)
+	if !ok {
+		return x.Name
+	}
+	// packages which could not be imported are empty
+	if imported := pkgName.Imported(); imported.Name() != "" && imported.Scope().Len() > 0 {
+		return imported.Name()
+	}
+	return packageNameFromPath(pkgName.Imported().Path())
+}
+
+var majorVersionRegex = regexp.MustCompile(`^v[0-9]+$`) //nolint:gochecknoglobals
+
+// packageNameFromPath is used when an import could not be resolved e.g. github.com/aarondl/null/v8 -> null,
+// gopkg.in/yaml.v3 -> yaml
+func packageNameFromPath(importPath string) string {
+	base := path.Base(importPath)
+	if majorVersionRegex.MatchString(base) {
+		base = path.Base(path.Dir(importPath))
+	}
+	if strings.HasPrefix(importPath, "gopkg.in/") {
+		base = strings.Split(base, ".")[0]
+	}
+	return strings.ReplaceAll(base, "-", "_")
+}
+
+func (p *modelsPackage) isModelsType(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	typeName, ok := p.pkg.TypesInfo.Uses[ident].(*types.TypeName)
+	return ok && typeName.Pkg() == p.pkg.Types
+}
+
+// typeType is the fieldType of a field which is declared outside the models package e.g. in an embedded struct
+func (p *modelsPackage) typeType(t types.Type) string {
+	qualifier := func(pkg *types.Package) string {
+		if pkg == p.pkg.Types {
+			return ""
+		}
+		return pkg.Name()
+	}
+	isModelsType := func(t types.Type) bool {
+		named, ok := t.(*types.Named)
+		return ok && named.Obj().Pkg() == p.pkg.Types
+	}
+
+	switch v := t.(type) {
+	case *types.Pointer:
+		if isModelsType(v.Elem()) {
+			return types.TypeString(v.Elem(), qualifier)
+		}
+	case *types.Slice:
+		elem := v.Elem()
+		if pointer, ok := elem.(*types.Pointer); ok && isModelsType(pointer.Elem()) {
+			elem = pointer.Elem()
+		}
+		return p.typeType(elem) + "Slice"
+	}
+	return types.TypeString(t, qualifier)
+}
+
+// parseStructVarFieldNames returns the field names of a struct variable like TableNames or ViewNames
+// e.g. var TableNames = struct { User string }{ User: "user" } -> User
+func (p *modelsPackage) parseStructVarFieldNames(name string) []string {
+	v, ok := p.pkg.Types.Scope().Lookup(name).(*types.Var)
+	if !ok {
+		p.logger.Warn("could not find "+name+" in the sqlboiler models, this could lead to problems if "+
+			"you're using plural table names", "directory", p.pkg.Dir)
+		return nil
+	}
+	structType, ok := v.Type().Underlying().(*types.Struct)
+	if !ok {
+		p.logger.Warn(name+" in the sqlboiler models is not a struct", "type", v.Type().String())
+		return nil
+	}
+	names := make([]string, structType.NumFields())
+	for i := range names {
+		names[i] = structType.Field(i).Name()
+	}
+	return names
+}
+
+// parseEnums returns the enums sqlboiler generates for enum columns, these are constant blocks which are documented
+// with Enum values for {{ titleCase table }}{{ titleCase column }} or with the name of the enum type in the database
+// when sqlboiler adds enum types e.g.
+// // Enum values for UserRole
+// const (
+// UserRoleAdmin  string = "admin"
+// )
+func (p *modelsPackage) parseEnums() []*modelsEnum {
+	var a []*modelsEnum
+	for _, file := range p.pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST || genDecl.Doc == nil {
+				continue
+			}
+			enumName, ok := enumNameFromDoc(genDecl.Doc.Text())
+			if !ok {
+				continue
+			}
+			a = append(a, &modelsEnum{
+				name: enumName,
+				BoilerEnum: &structs.BoilerEnum{
+					Name:         strcase.ToCamel(enumName),
+					Values:       p.parseEnumValues(genDecl),
+					DatabaseType: "string",
+					Description:  enumDescriptionFromDoc(genDecl.Doc.Text()),
+				},
+			})
+		}
+	}
+	return a
+}
+
+// modelsEnum is an enum with the name of the enum in the models package
+type modelsEnum struct {
+	*structs.BoilerEnum
+	name string
+}
+
+// resolveEnumFields sets the columns of all enums. A column uses an enum when its field is typed with the enum type or
+// when sqlboiler named the enum after the table and the column. The same enum type can be used by columns of several
+// tables. tableNames are the database names of the tables and views by model name.
+func (p *modelsPackage) resolveEnumFields(enums []*modelsEnum, tableNames map[string]string) error {
+	byName := make(map[string]*modelsEnum, len(enums))
+	for _, enum := range enums {
+		byName[enum.name] = enum
+	}
+
+	modelNames := make([]string, 0, len(tableNames))
+	for modelName := range tableNames {
+		modelNames = append(modelNames, modelName)
+	}
+	sort.Strings(modelNames)
+
+	scope := p.pkg.Types.Scope()
+	for _, modelName := range modelNames {
+		typeName, ok := scope.Lookup(modelName).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		structType, ok := typeName.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		for _, field := range p.structFields(modelName, structType) {
+			enum, ok := byName[p.fieldType(field.Var)]
+			if !ok {
+				column := strings.Split(reflect.StructTag(field.tag).Get("boil"), ",")[0]
+				if column == "" || column == "-" {
+					continue
+				}
+				enum, ok = byName[strmangle.TitleCase(tableNames[modelName])+strmangle.TitleCase(column)]
+				if !ok {
+					continue
+				}
+			}
+			enum.Fields = append(enum.Fields, &structs.BoilerEnumField{ModelName: modelName, FieldKey: field.Name()})
+		}
+	}
+
+	var unresolved []string
+	for _, enum := range enums {
+		if len(enum.Fields) == 0 {
+			unresolved = append(unresolved, enum.name)
+			continue
+		}
+		enum.ModelName = enum.Fields[0].ModelName
+		enum.ModelFieldKey = enum.Fields[0].FieldKey
+	}
+	if len(unresolved) > 0 {
+		return fmt.Errorf("could not find the columns of the enums %v in the sqlboiler models, the enums should be "+
+			"named after their table and column or a column should be typed with the enum", strings.Join(unresolved, ", "))
+	}
+	return nil
+}
+
+// parseStructVarValues returns the string values of a struct variable like TableNames or ViewNames by field name
+// e.g. var TableNames = struct { User string }{ User: "user" } -> User: user
+func (p *modelsPackage) parseStructVarValues(name string) map[string]string {
+	values := map[string]string{}
+	for _, file := range p.pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok || len(valueSpec.Names) != 1 || valueSpec.Names[0].Name != name || len(valueSpec.Values) != 1 {
+					continue
+				}
+				literal, ok := valueSpec.Values[0].(*ast.CompositeLit)
+				if !ok {
+					continue
+				}
+				for _, element := range literal.Elts {
+					keyValue, ok := element.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					key, ok := keyValue.Key.(*ast.Ident)
+					if !ok {
+						continue
+					}
+					if value, ok := p.pkg.TypesInfo.Types[keyValue.Value]; ok && value.Value != nil &&
+						value.Value.Kind() == constant.String {
+						values[key.Name] = constant.StringVal(value.Value)
+					}
+				}
+			}
+		}
+	}
+	return values
+}
+
+func enumNameFromDoc(doc string) (string, bool) {
+	for _, line := range strings.Split(doc, "\n") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(line), "Enum values for "); ok && name != "" {
+			return strings.TrimSpace(name), true
+		}
+	}
+	return "", false
+}
+
+// enumDescriptionFromDoc returns the comment of an enum without the line sqlboiler uses to mark the enum
+func enumDescriptionFromDoc(doc string) string {
+	var lines []string
+	for _, line := range strings.Split(doc, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "Enum values for ") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func (p *modelsPackage) parseEnumValues(genDecl *ast.GenDecl) []*structs.BoilerEnumValue {
+	var a []*structs.BoilerEnumValue
+	for _, spec := range genDecl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		for _, name := range valueSpec.Names {
+			c, ok := p.pkg.TypesInfo.Defs[name].(*types.Const)
+			if !ok || c.Val().Kind() != constant.String {
+				continue
+			}
+			a = append(a, &structs.BoilerEnumValue{
+				Name: name.Name,
+			})
+		}
+	}
+	return a
+}
@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/logging"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
+)
+
+const modelsSource = `package models
+
+import (
+	stdtime "time"
+
+	nullv8 "github.com/aarondl/null/v8"
+)
+
+var TableNames = struct {
+	Account string
+}{
+	Account: "account",
+}
+
+// Enum values for AccountStatus
+const (
+	AccountStatusActive  string = "active"
+	AccountStatusBlocked string = "blocked"
+)
+
+type Timestamps struct {
+	// Moment of creation
+	CreatedAt stdtime.Time
+	DeletedAt nullv8.Time
+}
+
+// Account is an object representing the database table.
+// Accounts of the users
+type Account struct {
+	ID uint
+	// Whether the account can log in
+	Status   string
+	Email    nullv8.String // Address to send mail to
+	Settings map[string]string
+	OnSave   func() error
+	Timestamps
+
+	R *accountR
+}
+
+type accountR struct {
+	Parent   *Account
+	Children []*Account
+}
+`
+
+func TestLoadModelsPackage(t *testing.T) {
+	p, err := loadModelsPackage(writeTestModels(t, modelsSource), logging.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields, _ := p.parseStructFields()
+	for k, want := range map[string]string{
+		"Account.ID":           "uint",
+		"Account.Email":        "null.String",
+		"Account.Settings":     "map[string]string",
+		"Account.OnSave":       "func() error",
+		"Account.CreatedAt":    "time.Time",
+		"Account.DeletedAt":    "null.Time",
+		"Account.R":            "accountR",
+		"accountR.Parent":      "Account",
+		"accountR.Children":    "AccountSlice",
+		"Timestamps.CreatedAt": "time.Time",
+	} {
+		if got := fields[k]; got != want {
+			t.Errorf("%v: got %q, want %q", k, got, want)
+		}
+	}
+
+	if tableNames := p.parseStructVarFieldNames("TableNames"); len(tableNames) != 1 || tableNames[0] != "Account" {
+		t.Errorf("got table names %v, want [Account]", tableNames)
+	}
+	descriptions := p.parseDescriptions()
+	for k, want := range map[string]string{
+		"Account":           "Accounts of the users",
+		"Account.ID":        "",
+		"Account.Status":    "Whether the account can log in",
+		"Account.Email":     "Address to send mail to",
+		"Account.CreatedAt": "Moment of creation",
+	} {
+		if got := descriptions[k]; got != want {
+			t.Errorf("description of %v: got %q, want %q", k, got, want)
+		}
+	}
+
+	if enums := p.parseEnums(); len(enums) != 1 || enums[0].name != "AccountStatus" || len(enums[0].Values) != 2 {
+		t.Errorf("got enums %+v, want AccountStatus with 2 values", enums)
+	}
+}
+
+const enumModelsSource = `package models
+
+var TableNames = struct {
+	Message       string
+	MessageLetter string
+	Post          string
+}{
+	Message:       "message",
+	MessageLetter: "message_letter",
+	Post:          "post",
+}
+
+// Enum values for MessageLetterStatus
+const (
+	MessageLetterStatusDraft string = "draft"
+	MessageLetterStatusSent  string = "sent"
+)
+
+// Enum values for Visibility
+const (
+	VisibilityPublic  Visibility = "public"
+	VisibilityPrivate Visibility = "private"
+)
+
+type Visibility string
+
+type Message struct {
+	ID           int        ` + "`boil:\"id\"`" + `
+	LetterStatus string     ` + "`boil:\"letter_status\"`" + `
+	Visibility   Visibility ` + "`boil:\"visibility\"`" + `
+}
+
+type MessageLetter struct {
+	ID   int    ` + "`boil:\"id\"`" + `
+	Kind string ` + "`boil:\"kind\"`" + `
+}
+
+type Post struct {
+	ID         int        ` + "`boil:\"id\"`" + `
+	Visibility Visibility ` + "`boil:\"post_visibility\"`" + `
+}
+`
+
+func TestGetBoilerModelsEnums(t *testing.T) {
+	_, enums, err := GetBoilerModels(writeTestModels(t, enumModelsSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		enum   string
+		fields []structs.BoilerEnumField
+	}{
+		// the longest table name which is a prefix is message_letter but the column is message.letter_status
+		{enum: "MessageLetterStatus", fields: []structs.BoilerEnumField{{ModelName: "Message", FieldKey: "LetterStatus"}}},
+		// an enum type which is shared by several tables
+		{enum: "Visibility", fields: []structs.BoilerEnumField{
+			{ModelName: "Message", FieldKey: "Visibility"},
+			{ModelName: "Post", FieldKey: "Visibility"},
+		}},
+	} {
+		var fields []structs.BoilerEnumField
+		for _, enum := range enums {
+			if enum.Name == test.enum {
+				for _, field := range enum.Fields {
+					fields = append(fields, *field)
+				}
+			}
+		}
+		if !reflect.DeepEqual(fields, test.fields) {
+			t.Errorf("%v: got fields %+v, want %+v", test.enum, fields, test.fields)
+		}
+	}
+
+	unresolved := strings.Replace(enumModelsSource, "letter_status", "letter_state", 1)
+	if _, _, err := GetBoilerModels(writeTestModels(t, unresolved)); err == nil ||
+		!strings.Contains(err.Error(), "MessageLetterStatus") {
+		t.Errorf("got error %v, want an error about MessageLetterStatus", err)
+	}
+}
+
+// writeTestModels writes a models package with a go.mod to a temporary directory
+func writeTestModels(t *testing.T, source string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/models\n\ngo 1.21\n")
+	writeTestFile(t, filepath.Join(dir, "models.go"), source)
+	return dir
+}
+
+func writeTestFile(t *testing.T, name string, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,31 +2,51 @@ package cache
 
 import (
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
-	"io/ioutil"
-	"path/filepath"
-	"regexp"
+	"log/slog"
 	"sort"
 	"strings"
 	"unicode"
 
-	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
-
 	"github.com/iancoleman/strcase"
-	"github.com/rs/zerolog/log"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/logging"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/naming"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
 )
 
 // parseModelsAndFieldsFromBoiler since these are like User.ID, User.Organization and we want them grouped by
 // modelName and their belonging fields.
-func GetBoilerModels(dir string) ([]*structs.BoilerModel, []*structs.BoilerEnum) { //nolint:gocognit,gocyclo
-	boilerTypeMap, _, boilerTypeOrder := parseBoilerFile(dir)
+func GetBoilerModels(dir string) ([]*structs.BoilerModel, []*structs.BoilerEnum, error) {
+	return getBoilerModels(dir, logging.Default(), naming.Strategy{})
+}
+
+func getBoilerModels(dir string, logger *slog.Logger, strategy naming.Strategy) ([]*structs.BoilerModel, []*structs.BoilerEnum, error) { //nolint:gocognit,gocyclo
+	modelsPackage, err := loadModelsPackage(dir, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not load the sqlboiler models in %v: %w", dir, err)
+	}
+	boilerTypeMap, boilerTypeOrder := modelsPackage.parseStructFields()
+	descriptions := modelsPackage.parseDescriptions()
 	boilerTypes := getSortedBoilerTypes(boilerTypeMap, boilerTypeOrder)
-	tableNames := parseTableNames(dir)
-	viewNames := parseViews(dir)
-	allTableNames := append(tableNames, viewNames...)
-	enums := parseEnums(dir, allTableNames)
+	tableNames := modelsPackage.parseStructVarFieldNames("TableNames")
+	viewNames := modelsPackage.parseStructVarFieldNames("ViewNames")
+
+	tableDatabaseNames := modelsPackage.parseStructVarValues("TableNames")
+	for modelName, viewName := range modelsPackage.parseStructVarValues("ViewNames") {
+		tableDatabaseNames[modelName] = viewName
+	}
+	for _, modelName := range append(tableNames, viewNames...) {
+		if _, ok := tableDatabaseNames[modelName]; !ok {
+			tableDatabaseNames[modelName] = strcase.ToSnake(modelName)
+		}
+	}
+	modelsEnums := modelsPackage.parseEnums()
+	if err := modelsPackage.resolveEnumFields(modelsEnums, tableDatabaseNames); err != nil {
+		return nil, nil, err
+	}
+	enums := make([]*structs.BoilerEnum, len(modelsEnums))
+	for i, enum := range modelsEnums {
+		enums[i] = enum.BoilerEnum
+	}
 
 	// sortedModelNames is needed to get the right order back of the structs since we want the same order every time
 	// this program has ran.
@@ -56,7 +76,6 @@ func GetBoilerModels(dir string) ([]*structs.BoilerModel, []*structs.BoilerEnum)
 
 		// result in e.g. ID
 		boilerFieldName := splitted[1]
-		somethingFcm := strings.Contains(strings.ToLower(modelName), "fcm")
 
 		// handle names with lowercase e.g. userR, userL or other sqlboiler extra's
 		if IsFirstCharacterLowerCase(modelName) {
@@ -68,15 +87,12 @@ func GetBoilerModels(dir string) ([]*structs.BoilerModel, []*structs.BoilerEnum)
 				isArray := strings.HasSuffix(boiler.Type, "Slice")
 				boilerType := strings.TrimSuffix(boiler.Type, "Slice")
 
-				if somethingFcm {
-					fmt.Println("boilerType", boilerType)
-					fmt.Println("boilerFieldName", boilerFieldName)
-				}
+				logger.Debug("found relation", "model", modelName, "field", boilerFieldName, "type", boilerType)
 
 				relationField := &structs.BoilerField{
 					Name:             boilerFieldName,
 					RelationshipName: strings.TrimSuffix(boilerFieldName, "ID"),
-					PluralName:       Plural(boilerFieldName),
+					PluralName:       strategy.WordPlural(boilerFieldName),
 					Type:             boilerType,
 					IsRelation:       true,
 					IsRequired:       false,
@@ -100,7 +116,7 @@ func GetBoilerModels(dir string) ([]*structs.BoilerModel, []*structs.BoilerEnum)
 
 		addFieldToMap(fieldsPerModelName, modelName, &structs.BoilerField{
 			Name:             boilerFieldName,
-			PluralName:       Plural(boilerFieldName),
+			PluralName:       strategy.WordPlural(boilerFieldName),
 			Type:             boiler.Type,
 			IsRelation:       isRelation,
 			IsRequired:       isRequired(boiler.Type),
@@ -108,6 +124,7 @@ func GetBoilerModels(dir string) ([]*structs.BoilerModel, []*structs.BoilerEnum)
 			IsForeignKey:     isRelation,
 			InTable:          true,
 			InTableNotID:     !isID,
+			Description:      descriptions[boiler.Name],
 		})
 	}
 	sort.Strings(modelNames)
@@ -116,7 +133,7 @@ func GetBoilerModels(dir string) ([]*structs.BoilerModel, []*structs.BoilerEnum)
 	models := make([]*structs.BoilerModel, len(modelNames))
 	for i, modelName := range modelNames {
 		fields := fieldsPerModelName[modelName]
-		tableName := findTableName(tableNames, modelName)
+		tableName := findTableName(tableNames, modelName, strategy)
 
 		var hasPrimaryStringID bool
 		IDField := findBoilerField(fields, "ID")
@@ -133,12 +150,13 @@ func GetBoilerModels(dir string) ([]*structs.BoilerModel, []*structs.BoilerEnum)
 		models[i] = &structs.BoilerModel{
 			Name:               modelName,
 			TableName:          tableName,
-			PluralName:         Plural(modelName),
+			PluralName:         strategy.WordPlural(modelName),
 			Fields:             fields,
 			Enums:              filterEnumsByModelName(enums, modelName),
 			HasPrimaryStringID: hasPrimaryStringID,
 			HasDeletedAt:       hasDeletedAt,
 			IsView:             SliceContains(viewNames, modelName),
+			Description:        descriptions[modelName],
 		}
 	}
 
@@ -182,15 +200,15 @@ func GetBoilerModels(dir string) ([]*structs.BoilerModel, []*structs.BoilerEnum)
 		}
 	}
 
-	return models, enums
+	return models, enums, nil
 }
 
 func getEnumByModelNameAndFieldName(enums []*structs.BoilerEnum, modelName string, fieldName string) *structs.BoilerEnum {
 	for _, e := range enums {
-		// fmt.Println("        ", e.ModelName, modelName)
-		// fmt.Println("        ", e.ModelFieldKey, fieldName)
-		if e.ModelName == modelName && e.ModelFieldKey == fieldName {
-			return e
+		for _, field := range e.Fields {
+			if field.ModelName == modelName && field.FieldKey == fieldName {
+				return e
+			}
 		}
 	}
 	return nil
@@ -199,8 +217,11 @@ func getEnumByModelNameAndFieldName(enums []*structs.BoilerEnum, modelName strin
 func filterEnumsByModelName(enums []*structs.BoilerEnum, modelName string) []*structs.BoilerEnum {
 	var a []*structs.BoilerEnum
 	for _, e := range enums {
-		if e.ModelName == modelName {
-			a = append(a, e)
+		for _, field := range e.Fields {
+			if field.ModelName == modelName {
+				a = append(a, e)
+				break
+			}
 		}
 	}
 	return a
@@ -215,7 +236,7 @@ func findBoilerField(fields []*structs.BoilerField, fieldName string) *structs.B
 	return nil
 }
 
-func findTableName(tableNames []string, modelName string) string {
+func findTableName(tableNames []string, modelName string, strategy naming.Strategy) string {
 	for _, tableName := range tableNames {
 		if modelName == tableName {
 			return tableName
@@ -224,13 +245,20 @@ func findTableName(tableNames []string, modelName string) string {
 
 	// if database name is plural
 	for _, tableName := range tableNames {
-		if Plural(modelName) == tableName {
+		if strategy.WordPlural(modelName) == tableName {
 			return tableName
 		}
 	}
 	return modelName
 }
 
+func isUpperRune(s rune) bool {
+	if !unicode.IsUpper(s) && unicode.IsLetter(s) {
+		return false
+	}
+	return true
+}
+
 func isRequired(boilerType string) bool {
 	if strings.HasPrefix(boilerType, "null.") ||
 		strings.HasPrefix(boilerType, "types.Null") ||
@@ -279,264 +307,3 @@ func getSortedBoilerTypes(boilerTypeMap map[string]string, boilerTypeOrder map[s
 	}
 	return //nolint:nakedret
 }
-
-var tableNameRegex = regexp.MustCompile(`\s*(.*[^ ])\s*string`) //nolint:gochecknoglobals
-
-func parseTableNames(dir string) []string {
-	dir, err := filepath.Abs(dir)
-	errMessage := "could not open boiler table names file, this could not lead to problems if you're " +
-		"using plural table names"
-	if err != nil {
-		log.Warn().Err(err).Msg(errMessage)
-		return nil
-	}
-	content, err := ioutil.ReadFile(filepath.Join(dir, "boil_table_names.go"))
-	if err != nil {
-		log.Warn().Err(err).Msg(errMessage)
-		return nil
-	}
-	tableNamesMatches := tableNameRegex.FindAllStringSubmatch(string(content), -1)
-	tableNames := make([]string, len(tableNamesMatches))
-	for i, tableNameMatch := range tableNamesMatches {
-		tableNames[i] = tableNameMatch[1]
-	}
-	return tableNames
-}
-
-func parseViews(dir string) []string {
-	dir, err := filepath.Abs(dir)
-	errMessage := "could not open boiler table names file, this could not lead to problems if you're " +
-		"using plural table names"
-	if err != nil {
-		log.Warn().Err(err).Msg(errMessage)
-		return nil
-	}
-	content, err := ioutil.ReadFile(filepath.Join(dir, "boil_view_names.go"))
-	if err != nil {
-		log.Warn().Err(err).Msg(errMessage)
-		return nil
-	}
-	viewNamesMatches := tableNameRegex.FindAllStringSubmatch(string(content), -1)
-	viewNames := make([]string, len(viewNamesMatches))
-	for i, tableNameMatch := range viewNamesMatches {
-		viewNames[i] = tableNameMatch[1]
-	}
-	return viewNames
-}
-
-var (
-	enumRegex       = regexp.MustCompile(`// Enum values for (.*)\nconst\s\(\n(:?(.|\n)*?)\n\)`) //nolint:gochecknoglobals
-	enumValuesRegex = regexp.MustCompile(`\s(\w+)\s*string\s*=\s*"(\w+)"`)                       //nolint:gochecknoglobals
-)
-
-func parseEnums(dir string, allTableNames []string) []*structs.BoilerEnum {
-	dir, err := filepath.Abs(dir)
-	errMessage := "could not open enum names file, this could not lead to problems if you're " +
-		"using enums in your db"
-	if err != nil {
-		log.Warn().Err(err).Msg(errMessage)
-		return nil
-	}
-	content, err := ioutil.ReadFile(filepath.Join(dir, "boil_types.go"))
-	if err != nil {
-		log.Warn().Err(err).Msg(errMessage)
-		return nil
-	}
-	matches := enumRegex.FindAllStringSubmatch(string(content), -1)
-	a := make([]*structs.BoilerEnum, len(matches))
-	for i, match := range matches {
-		// 1: messageLetterStatus
-		// 2: status
-		// 3: contents
-		modelName, fieldKey := stripLastWord(match[1], allTableNames)
-		name := strcase.ToCamel(match[1])
-
-		a[i] = &structs.BoilerEnum{
-			Name:          name,
-			ModelName:     strcase.ToCamel(modelName),
-			ModelFieldKey: strcase.ToCamel(fieldKey),
-			Values:        parseEnumValues(match[2]),
-		}
-	}
-	return a
-}
-
-func stripLastWord(v string, allTableNames []string) (string, string) {
-	// longest tables first
-	sort.Slice(allTableNames, func(i, j int) bool {
-		return len(allTableNames[i]) > len(allTableNames[j])
-	})
-
-	for _, tableName := range allTableNames {
-		if strings.HasPrefix(v, tableName) {
-			return tableName, strings.TrimPrefix(v, tableName)
-		}
-	}
-	log.Warn().Str("enumName", v).Msg("could not find model by enum")
-	return "", ""
-}
-
-func isUpperRune(s rune) bool {
-	if !unicode.IsUpper(s) && unicode.IsLetter(s) {
-		return false
-	}
-	return true
-}
-
-func parseEnumValues(content string) []*structs.BoilerEnumValue {
-	matches := enumValuesRegex.FindAllStringSubmatch(content, -1)
-	a := make([]*structs.BoilerEnumValue, len(matches))
-	for i, match := range matches {
-		// 1: message_letter
-		// 2: status
-		// 2: status
-		// 3: contents
-		a[i] = &structs.BoilerEnumValue{
-			Name: match[1],
-		}
-	}
-	return a
-}
-
-// will return StructName.key
-// e.g.
-// Address.ID: null.Integer
-// Address.Longitude: null.String
-// Address.Latitude : null.Decimal
-// needed to generate the right convert code
-func parseBoilerFile(dir string) (map[string]string, map[string]string, map[string]int) { //nolint:gocognit,gocyclo
-	fieldsMap := make(map[string]string)
-	structsMap := make(map[string]string)
-	fieldsOrder := make(map[string]int)
-
-	dir, err := filepath.Abs(dir)
-	if err != nil {
-		log.Err(err).Msg("parseBoilerFile filepath.Abs error")
-		return fieldsMap, structsMap, fieldsOrder
-	}
-	files, err := ioutil.ReadDir(dir)
-	if err != nil {
-		log.Err(err).Msg("parseBoilerFile ioutil.ReadDir error")
-		return fieldsMap, structsMap, fieldsOrder
-	}
-
-	fset := token.NewFileSet()
-	for _, file := range files {
-		// only pick .go files and ignore test files
-		if !strings.HasSuffix(strings.ToLower(file.Name()), ".go") ||
-			strings.HasSuffix(strings.ToLower(file.Name()), "_test.go") {
-			continue
-		}
-
-		filename := filepath.Join(dir, file.Name())
-		if src, err := parser.ParseFile(fset, filename, nil, parser.ParseComments); err == nil { //nolint:nestif
-			var i int
-			for _, decl := range src.Decls {
-				// TODO: make cleaner
-				typeDecl, ok := decl.(*ast.GenDecl)
-				if !ok {
-					continue
-				}
-
-				for _, spec := range typeDecl.Specs {
-					safeTypeSpec, ok := spec.(*ast.TypeSpec)
-					if !ok {
-						continue
-					}
-
-					structsMap[safeTypeSpec.Name.String()] = safeTypeSpec.Name.String()
-
-					safeStructDecl, ok := safeTypeSpec.Type.(*ast.StructType)
-					if !ok {
-						continue
-					}
-
-					for _, field := range safeStructDecl.Fields.List {
-						switch xv := field.Type.(type) {
-						case *ast.StarExpr:
-
-							if len(field.Names) > 0 {
-								name := field.Names[0].Name
-
-								if si, ok := xv.X.(*ast.Ident); ok {
-									k := safeTypeSpec.Name.String() + "." + name
-									//https://stackoverflow.com/questions/28246970/how-to-parse-a-method-declaration
-									fieldsMap[k] = si.Name
-									fieldsOrder[k] = i
-								}
-							} // else {
-							// fmt.Println("len(field.Names) == 0", field)
-						//	}
-						case *ast.ArrayType:
-
-							name := field.Names[0].Name
-
-							if !IsFirstCharacterLowerCase(name) {
-								//nolint:errcheck //TODO: handle errors
-								t, _ := field.Type.(*ast.ArrayType)
-
-								k := safeTypeSpec.Name.String() + "." + name
-
-								fieldsMap[k] = t.Elt.(*ast.Ident).Name + "Slice"
-								fieldsOrder[k] = i
-							}
-
-						case *ast.SelectorExpr:
-							//nolint:errcheck //TODO: handle errors
-							t, _ := field.Type.(*ast.SelectorExpr)
-							name := field.Names[0].Name
-
-							k := safeTypeSpec.Name.String() + "." + name
-
-							fieldsMap[k] = t.X.(*ast.Ident).Name + "." + t.Sel.Name
-							fieldsOrder[k] = i
-
-						case *ast.Ident:
-							//nolint:errcheck //TODO: handle errors
-							t, _ := field.Type.(*ast.Ident) // The type as a string
-							typeName := t.Name
-							name := field.Names[0].Name // name as a string
-
-							k := safeTypeSpec.Name.String() + "." + name
-							fieldsMap[k] = typeName
-							fieldsOrder[k] = i
-
-						default:
-							fmt.Println("ignoring....", field.Names, field)
-						}
-						i++
-					}
-				}
-			}
-		}
-	}
-
-	//// To store the keys in slice in sorted order
-	//var keys []string
-	//for k := range fieldsMap {
-	//	keys = append(keys, k)
-	//}
-	//sort.Strings(keys)
-	//
-	//fmt.Println(" ")
-	//fmt.Println(" ")
-	//fmt.Println(" ")
-	//fmt.Println("START OF MAP DUMP")
-	//fmt.Println("START OF MAP DUMP")
-	//fmt.Println("START OF MAP DUMP")
-	//fmt.Println(" ")
-	//fmt.Println(" ")
-	//for _, key := range keys {
-	//	fmt.Println(key, ":", fieldsMap[key])
-	//}
-	//fmt.Println(" ")
-	//fmt.Println(" ")
-	//fmt.Println("END OF MAP DUMP")
-	//fmt.Println("END OF MAP DUMP")
-	//fmt.Println("END OF MAP DUMP")
-	//fmt.Println(" ")
-	//fmt.Println(" ")
-	//fmt.Println(" ")
-
-	return fieldsMap, structsMap, fieldsOrder
-}
@@ -1,46 +1,323 @@
 package customization
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/printer"
 	"go/token"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
 )
 
-func GetFunctionNamesFromDir(dir string, ignore []string) ([]string, error) {
-	var a []string
+// Function is a function or method which is defined by the user next to the generated code
+type Function struct {
+	// Receiver is the type name of the receiver without pointer e.g. queryResolver, empty for functions
+	Receiver string
+	Name     string
+	// Signature are the parameter and result types e.g. func(*dm.User) *fm.User
+	Signature string
+	// NormalizedSignature is the Signature with import paths instead of package names and any instead of interface{}
+	// e.g. func(*example.com/models/dm.User) *example.com/models/fm.User so signatures of files with other imports can
+	// be compared
+	NormalizedSignature string
+	// Position is the file and line of the declaration
+	Position string
+}
+
+// Key identifies the function in its package e.g. queryResolver.User or UserToGraphQL
+func (f Function) Key() string {
+	return FunctionKey(f.Receiver, f.Name)
+}
+
+// CheckSignature returns an error when the function has another signature than the generated function fn of a file
+// with the imports of FileImports
+func (f Function) CheckSignature(set *token.FileSet, fn *ast.FuncDecl, imports map[string]string) error {
+	if NormalizedSignature(fn.Type, imports) == f.NormalizedSignature {
+		return nil
+	}
+	return fmt.Errorf("%v in %v has signature %v but the generated %v has %v",
+		f.Key(), f.Position, f.Signature, fn.Name.Name, Signature(set, fn.Type))
+}
+
+// CheckSignatures returns an error for every function of which the generated function with the same key in the
+// generated file has another signature
+func CheckSignatures(fileName string, generated []byte, functions []Function) error {
+	set := token.NewFileSet()
+	node, err := parser.ParseFile(set, fileName, generated, 0)
+	if err != nil {
+		return fmt.Errorf("could not parse %v: %w", fileName, err)
+	}
+	byKey := make(map[string]Function, len(functions))
+	for _, fn := range functions {
+		byKey[fn.Key()] = fn
+	}
+
+	imports := FileImports(node)
+	var errs []error
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if function, ok := byKey[FunctionKey(ReceiverTypeName(fn), fn.Name.Name)]; ok {
+			if err := function.CheckSignature(set, fn, imports); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FunctionKey returns the key of a function or method in its package
+func FunctionKey(receiver string, name string) string {
+	if receiver == "" {
+		return name
+	}
+	return receiver + "." + name
+}
+
+// GetFunctionsFromDir returns the functions and methods of package packageName in dir, test files, files of other
+// packages and the ignored (generated) files are skipped
+func GetFunctionsFromDir(dir string, packageName string, ignore []string) ([]Function, error) {
+	set, pack, err := parsePackage(dir, packageName, ignore)
+	if err != nil || pack == nil {
+		return nil, err
+	}
+	var a []Function
+	for _, file := range pack.Files {
+		a = append(a, GetFunctionsFromAstFile(set, file)...)
+	}
+	return a, nil
+}
+
+// parsePackage parses package packageName in dir without the test files and the ignored files, the package is nil
+// when dir or the package does not exist
+func parsePackage(dir string, packageName string, ignore []string) (*token.FileSet, *ast.Package, error) {
 	set := token.NewFileSet()
-	// Use filter function to skip ignored files DURING parsing, not after
-	// This prevents parse errors from generated files that may be empty/malformed
 	filterFunc := func(info os.FileInfo) bool {
-		return !contains(ignore, info.Name())
+		return !contains(ignore, info.Name()) && !strings.HasSuffix(info.Name(), "_test.go")
 	}
 	packs, err := parser.ParseDir(set, dir, filterFunc, 0)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, nil
+			return set, nil, nil
+		}
+		return set, nil, fmt.Errorf("failed to parse package: %v", err)
+	}
+	return set, packs[packageName], nil
+}
+
+// GetFunctionsFromAstFile returns the top level functions and methods of the file
+func GetFunctionsFromAstFile(set *token.FileSet, node *ast.File) []Function {
+	imports := FileImports(node)
+	var a []Function
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		position := set.Position(fn.Pos())
+		a = append(a, Function{
+			Receiver:            ReceiverTypeName(fn),
+			Name:                fn.Name.Name,
+			Signature:           Signature(set, fn.Type),
+			NormalizedSignature: NormalizedSignature(fn.Type, imports),
+			Position:            fmt.Sprintf("%v:%d", filepath.Base(position.Filename), position.Line),
+		})
+	}
+	return a
+}
+
+// ReceiverTypeName returns the type name of the receiver without pointer and type parameters, empty for functions
+func ReceiverTypeName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// Signature prints the parameter and result types of a function without the names of the parameters so
+// func(ctx context.Context, id string) error and func(c context.Context, userID string) error are equal
+func Signature(set *token.FileSet, fn *ast.FuncType) string {
+	return signature(fn, func(expr ast.Expr) string {
+		return typeString(set, expr)
+	})
+}
+
+// NormalizedSignature prints the signature like Signature but with the import paths of imports instead of the package
+// names and any instead of interface{} so func(m *models.User) interface{} and func(m *dm.User) any are equal when
+// models and dm are aliases of the same import
+func NormalizedSignature(fn *ast.FuncType, imports map[string]string) string {
+	return signature(fn, func(expr ast.Expr) string {
+		return normalizedTypeString(expr, imports)
+	})
+}
+
+func signature(fn *ast.FuncType, typeString func(ast.Expr) string) string {
+	var b strings.Builder
+	b.WriteString("func(")
+	writeFieldTypes(&b, fn.Params, typeString)
+	b.WriteString(")")
+	if fn.Results == nil || len(fn.Results.List) == 0 {
+		return b.String()
+	}
+	b.WriteString(" ")
+	if len(fn.Results.List) == 1 && len(fn.Results.List[0].Names) <= 1 {
+		b.WriteString(typeString(fn.Results.List[0].Type))
+		return b.String()
+	}
+	b.WriteString("(")
+	writeFieldTypes(&b, fn.Results, typeString)
+	b.WriteString(")")
+	return b.String()
+}
+
+func writeFieldTypes(b *strings.Builder, fields *ast.FieldList, typeString func(ast.Expr) string) {
+	if fields == nil {
+		return
+	}
+	first := true
+	for _, field := range fields.List {
+		// func(a, b string) has two parameters in one field
+		count := max(len(field.Names), 1)
+		for i := 0; i < count; i++ {
+			if !first {
+				b.WriteString(", ")
+			}
+			first = false
+			b.WriteString(typeString(field.Type))
 		}
-		return nil, fmt.Errorf("failed to parse package: %v", err)
 	}
+}
+
+func typeString(set *token.FileSet, expr ast.Expr) string {
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, set, expr); err != nil {
+		return fmt.Sprintf("%T", expr)
+	}
+	return b.String()
+}
+
+// normalizedTypeString prints a copy of the type in which the package names are replaced by their import path and
+// empty interfaces by any
+func normalizedTypeString(expr ast.Expr, imports map[string]string) string {
+	if ellipsis, ok := expr.(*ast.Ellipsis); ok {
+		return "..." + normalizedTypeString(ellipsis.Elt, imports)
+	}
+	// the type is parsed again so the declaration of the file is not changed
+	copied, err := parser.ParseExpr(typeString(token.NewFileSet(), expr))
+	if err != nil {
+		return typeString(token.NewFileSet(), expr)
+	}
+	normalized := astutil.Apply(copied, nil, func(c *astutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.InterfaceType:
+			if n.Methods == nil || len(n.Methods.List) == 0 {
+				c.Replace(ast.NewIdent("any"))
+			}
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok && imports[x.Name] != "" {
+				c.Replace(ast.NewIdent(imports[x.Name] + "." + n.Sel.Name))
+			}
+		}
+		return true
+	})
+	return typeString(token.NewFileSet(), normalized.(ast.Expr))
+}
 
-	for _, pack := range packs {
-		for _, file := range pack.Files {
-			a = append(a, GetFunctionNamesFromAstFile(file)...)
+// FileImports returns the import paths of the file by the name they are used with, which is the alias or the package
+// name guessed from the import path e.g. boilergql for github.com/web-ridge/utils-go/boilergql/v3
+func FileImports(node *ast.File) map[string]string {
+	imports := make(map[string]string, len(node.Imports))
+	for _, spec := range node.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
 		}
+		if name == "_" || name == "." {
+			continue
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+var majorVersionRegex = regexp.MustCompile(`^v[0-9]+$`) //nolint:gochecknoglobals
+
+// importName guesses the package name of an import path without an alias, the last element without a major version
+// suffix e.g. boilergql for github.com/web-ridge/utils-go/boilergql/v3 or yaml for gopkg.in/yaml.v3
+func importName(importPath string) string {
+	elements := strings.Split(importPath, "/")
+	name := elements[len(elements)-1]
+	if majorVersionRegex.MatchString(name) && len(elements) > 1 {
+		name = elements[len(elements)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 && strings.HasPrefix(importPath, "gopkg.in/") {
+		name = name[:i]
+	}
+	return strings.TrimPrefix(name, "go-")
+}
+
+// GetStructFieldNamesFromDir returns the field names of the struct with the given name in package packageName, test
+// files, files of other packages and the ignored (generated) files are skipped
+func GetStructFieldNamesFromDir(dir string, packageName string, ignore []string, structName string) ([]string, error) {
+	_, pack, err := parsePackage(dir, packageName, ignore)
+	if err != nil || pack == nil {
+		return nil, err
+	}
+	var a []string
+	for _, file := range pack.Files {
+		a = append(a, GetStructFieldNamesFromAstFile(file, structName)...)
 	}
 	return a, nil
 }
 
-func GetFunctionNamesFromAstFile(node *ast.File) []string {
+func GetStructFieldNamesFromAstFile(node *ast.File, structName string) []string {
 	var a []string
 
 	ast.Inspect(node, func(n ast.Node) bool {
-		fn, ok := n.(*ast.FuncDecl)
-		if ok {
-			a = append(a, fn.Name.Name)
+		typeSpec, ok := n.(*ast.TypeSpec)
+		if !ok || typeSpec.Name.Name != structName {
+			return true
 		}
-		return true
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+		for _, field := range structType.Fields.List {
+			for _, name := range field.Names {
+				a = append(a, name.Name)
+			}
+		}
+		return false
 	})
 	return a
 }
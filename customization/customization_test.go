@@ -0,0 +1,264 @@
+package customization
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetFunctionsFromDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"convert_override_user.go": `package helpers
+
+import (
+	dm "example.com/models/dm"
+	"example.com/models/fm"
+)
+
+func UserToGraphQL(m *dm.User, ids ...string) (*fm.User, error) { return nil, nil }
+
+func (c *converter) UserToGraphQL(m *dm.User) *fm.User { return nil }
+
+func (c converter[T]) PostToGraphQL(a, b int) {}
+`,
+		"convert_override_user_test.go": "package helpers\n\nfunc CommentToGraphQL() {}\n",
+		"generated_convert.go":          "package helpers\n\nfunc ( broken\n",
+		"doc.go":                        "package other\n\nfunc TagToGraphQL() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := GetFunctionsFromDir(dir, "helpers", []string{"generated_convert.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Function{
+		{
+			Name:                "UserToGraphQL",
+			Signature:           "func(*dm.User, ...string) (*fm.User, error)",
+			NormalizedSignature: "func(*example.com/models/dm.User, ...string) (*example.com/models/fm.User, error)",
+			Position:            "convert_override_user.go:8",
+		},
+		{
+			Receiver:            "converter",
+			Name:                "UserToGraphQL",
+			Signature:           "func(*dm.User) *fm.User",
+			NormalizedSignature: "func(*example.com/models/dm.User) *example.com/models/fm.User",
+			Position:            "convert_override_user.go:10",
+		},
+		{
+			Receiver:            "converter",
+			Name:                "PostToGraphQL",
+			Signature:           "func(int, int)",
+			NormalizedSignature: "func(int, int)",
+			Position:            "convert_override_user.go:12",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+	if key := got[1].Key(); key != "converter.UserToGraphQL" {
+		t.Errorf("key of method is %v", key)
+	}
+
+	missing, err := GetFunctionsFromDir(filepath.Join(dir, "missing"), "helpers", nil)
+	if err != nil || missing != nil {
+		t.Errorf("missing directory should not return functions or an error, got %v %v", missing, err)
+	}
+}
+
+func TestCheckSignatures(t *testing.T) {
+	const generated = `package helpers
+
+import (
+	"context"
+
+	dm "example.com/models/dm"
+	"github.com/web-ridge/utils-go/boilergql/v3"
+)
+
+func UserToGraphQL(ctx context.Context, m *dm.User, extra interface{}) []map[string]interface{} { return nil }
+
+func (r *queryResolver) Node(ctx context.Context, id string) (boilergql.Node, error) { return nil, nil }
+`
+	tests := []struct {
+		name string
+		user string
+		err  string
+	}{
+		{
+			name: "same signature",
+			user: "import (\n\"context\"\n\ndm \"example.com/models/dm\"\n)\n\n" +
+				"func UserToGraphQL(c context.Context, user *dm.User, extra interface{}) []map[string]interface{} { return nil }\n",
+		},
+		{
+			name: "other alias and any",
+			user: "import (\n\"context\"\n\nmodels \"example.com/models/dm\"\n)\n\n" +
+				"func UserToGraphQL(ctx context.Context, m *models.User, extra any) []map[string]any { return nil }\n",
+		},
+		{
+			name: "aliased major version",
+			user: "import (\n\"context\"\n\ngql \"github.com/web-ridge/utils-go/boilergql/v3\"\n)\n\n" +
+				"func (r *queryResolver) Node(ctx context.Context, id string) (gql.Node, error) { return nil, nil }\n",
+		},
+		{
+			name: "other package with the same name",
+			user: "import (\n\"context\"\n\ndm \"example.com/other/dm\"\n)\n\n" +
+				"func UserToGraphQL(ctx context.Context, m *dm.User, extra any) []map[string]any { return nil }\n",
+			err: "UserToGraphQL in user.go:9 has signature func(context.Context, *dm.User, any) []map[string]any but " +
+				"the generated UserToGraphQL has func(context.Context, *dm.User, interface{}) []map[string]interface{}",
+		},
+		{
+			name: "other method",
+			user: "import \"context\"\n\nfunc (r *queryResolver) Node(ctx context.Context, id int) (any, error) { return nil, nil }\n",
+			err:  "queryResolver.Node in user.go:5 has signature func(context.Context, int) (any, error)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := token.NewFileSet()
+			node, err := parser.ParseFile(set, "user.go", "package helpers\n\n"+tt.user, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = CheckSignatures("generated.go", []byte(generated), GetFunctionsFromAstFile(set, node))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestGetStructFieldNamesFromDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"resolver.go":      "package resolvers\n\ntype Resolver struct {\n\tdb *sql.DB\n}\n",
+		"resolver_test.go": "package resolvers\n\ntype Resolver struct {\n\tlogger ResolverLogger\n}\n",
+		"doc.go":           "package other\n\ntype Resolver struct {\n\tlogger ResolverLogger\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := GetStructFieldNamesFromDir(dir, "resolvers", nil, "Resolver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"db"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestOverrideStubs(t *testing.T) {
+	const generated = `package helpers
+
+import (
+	"context"
+
+	dm "example.com/models/dm"
+	fm "example.com/models/fm"
+)
+
+// UserToGraphQL converts a user
+func UserToGraphQL(m *dm.User, ids ...string) *fm.User { return nil }
+
+func Save(_ context.Context) {}
+
+func (r *mutationResolver) CreateUser(ctx context.Context, input fm.UserCreateInput) (*fm.User, error) {
+	// create the user
+	return nil, nil
+}
+`
+	tests := []struct {
+		name     string
+		key      string
+		copyBody bool
+		fileName string
+		content  string
+	}{
+		{
+			name:     "function",
+			key:      "UserToGraphQL",
+			fileName: "convert_override_user_to_graph_ql.go",
+			content: `package helpers
+
+import (
+	dm "example.com/models/dm"
+	fm "example.com/models/fm"
+)
+
+// UserToGraphQL overrides the generated function which is available as originalUserToGraphQL
+func UserToGraphQL(m *dm.User, ids ...string) *fm.User {
+	return originalUserToGraphQL(m, ids...)
+}
+`,
+		},
+		{
+			name:     "unnamed parameter",
+			key:      "Save",
+			fileName: "convert_override_save.go",
+			content: `package helpers
+
+import "context"
+
+// Save overrides the generated function which is available as originalSave
+func Save(arg0 context.Context) {
+	originalSave(arg0)
+}
+`,
+		},
+		{
+			name:     "copied resolver",
+			key:      "mutationResolver.CreateUser",
+			copyBody: true,
+			fileName: "convert_override_mutation_resolver_create_user.go",
+			content: `package helpers
+
+import (
+	"context"
+
+	fm "example.com/models/fm"
+)
+
+// CreateUser was copied from generated.go and is not generated anymore
+func (r *mutationResolver) CreateUser(ctx context.Context, input fm.UserCreateInput) (*fm.User, error) {
+	// create the user
+	return nil, nil
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubs, err := OverrideStubs("generated.go", []byte(generated), "convert", []string{tt.key}, tt.copyBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stubs) != 1 {
+				t.Fatalf("expected one stub, got %v", len(stubs))
+			}
+			if stubs[0].Function.Key() != tt.key || stubs[0].FileName != tt.fileName {
+				t.Errorf("got stub %v in %v", stubs[0].Function.Key(), stubs[0].FileName)
+			}
+			if string(stubs[0].Content) != tt.content {
+				t.Errorf("got:\n%s\nwant:\n%s", stubs[0].Content, tt.content)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package customization
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"golang.org/x/tools/imports"
+)
+
+// Stub is a user owned file which overrides a generated function
+type Stub struct {
+	Function Function
+	// FileName is the base name of the file e.g. convert_override_user_to_graph_ql.go
+	FileName string
+	Content  []byte
+}
+
+// StubFileName returns the file name of the stub of a function or method key e.g.
+// convert_override_user_to_graph_ql.go for UserToGraphQL with prefix convert
+func StubFileName(prefix string, key string) string {
+	return prefix + "_override_" + strcase.ToSnake(strings.ReplaceAll(key, ".", "_")) + ".go"
+}
+
+// OverrideStubs creates a stub for every function in generated of which the key is in keys. A stub calls
+// original{{Name}}, the name the generated function gets once the stub exists, or contains a copy of the generated
+// body when copyBody is true e.g. for resolvers which are left out instead of renamed.
+func OverrideStubs(fileName string, generated []byte, prefix string, keys []string, copyBody bool) ([]Stub, error) {
+	set := token.NewFileSet()
+	node, err := parser.ParseFile(set, fileName, generated, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %v: %w", fileName, err)
+	}
+
+	fileImports := FileImports(node)
+	var stubs []Stub
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		function := Function{
+			Receiver:            ReceiverTypeName(fn),
+			Name:                fn.Name.Name,
+			Signature:           Signature(set, fn.Type),
+			NormalizedSignature: NormalizedSignature(fn.Type, fileImports),
+		}
+		if !contains(keys, function.Key()) {
+			continue
+		}
+
+		var b bytes.Buffer
+		fmt.Fprintf(&b, "package %v\n\n", node.Name.Name)
+		for _, spec := range node.Imports {
+			b.WriteString("import ")
+			if spec.Name != nil {
+				b.WriteString(spec.Name.Name + " ")
+			}
+			b.WriteString(spec.Path.Value + "\n")
+		}
+		if copyBody {
+			fmt.Fprintf(&b, "\n// %v was copied from %v and is not generated anymore\n", fn.Name.Name, fileName)
+			if err := printer.Fprint(&b, set, &printer.CommentedNode{Node: stripDoc(fn), Comments: node.Comments}); err != nil {
+				return nil, fmt.Errorf("could not print %v: %w", function.Key(), err)
+			}
+		} else {
+			fmt.Fprintf(&b, "\n// %v overrides the generated function which is available as original%v\n",
+				fn.Name.Name, fn.Name.Name)
+			if err := writeOriginalCall(&b, set, fn); err != nil {
+				return nil, fmt.Errorf("could not print %v: %w", function.Key(), err)
+			}
+		}
+		b.WriteString("\n")
+
+		content, err := imports.Process(fileName, b.Bytes(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not format stub of %v: %w", function.Key(), err)
+		}
+		stubs = append(stubs, Stub{
+			Function: function,
+			FileName: StubFileName(prefix, function.Key()),
+			Content:  content,
+		})
+	}
+	return stubs, nil
+}
+
+// writeOriginalCall writes the signature of fn with a body which calls original{{Name}} with the same arguments
+func writeOriginalCall(b *bytes.Buffer, set *token.FileSet, fn *ast.FuncDecl) error {
+	var args []string
+	variadic := false
+	index := 0
+	params := &ast.FieldList{}
+	for _, field := range fn.Type.Params.List {
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{{}}
+		}
+		var named []*ast.Ident
+		for _, name := range names {
+			argument := name.Name
+			if argument == "" || argument == "_" {
+				argument = fmt.Sprintf("arg%d", index)
+			}
+			index++
+			named = append(named, ast.NewIdent(argument))
+			args = append(args, argument)
+		}
+		_, variadic = field.Type.(*ast.Ellipsis)
+		params.List = append(params.List, &ast.Field{Names: named, Type: field.Type})
+	}
+	if variadic {
+		args[len(args)-1] += "..."
+	}
+
+	call := "original" + fn.Name.Name + "(" + strings.Join(args, ", ") + ")"
+	recv := fn.Recv
+	if recv != nil && len(recv.List) > 0 {
+		field := recv.List[0]
+		receiver := "r"
+		if len(field.Names) > 0 && field.Names[0].Name != "_" {
+			receiver = field.Names[0].Name
+		}
+		recv = &ast.FieldList{List: []*ast.Field{{Names: []*ast.Ident{ast.NewIdent(receiver)}, Type: field.Type}}}
+		call = receiver + "." + call
+	}
+	if fn.Type.Results != nil && len(fn.Type.Results.List) > 0 {
+		call = "return " + call
+	}
+
+	stub := &ast.FuncDecl{
+		Recv: recv,
+		Name: fn.Name,
+		Type: &ast.FuncType{TypeParams: fn.Type.TypeParams, Params: params, Results: fn.Type.Results},
+	}
+	if err := printer.Fprint(b, set, stub); err != nil {
+		return err
+	}
+	b.WriteString(" {\n\t" + call + "\n}")
+	return nil
+}
+
+func stripDoc(fn *ast.FuncDecl) *ast.FuncDecl {
+	withoutDoc := *fn
+	withoutDoc.Doc = nil
+	return &withoutDoc
+}
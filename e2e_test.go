@@ -0,0 +1,114 @@
+package gbgen
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/cache"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
+)
+
+var e2e = flag.Bool("e2e", false, "generate a GraphQL API for testdata/e2e and run its tests against sqlite") //nolint:gochecknoglobals
+
+// e2eScope scopes posts to the user of the request so the tests can check scoped lists and mutations
+var e2eScope = &AuthorizationScope{ //nolint:gochecknoglobals
+	ImportPath:        goldenModule + "/auth",
+	ImportAlias:       "auth",
+	ScopeResolverName: "UserIDFromContext",
+	BoilerColumnName:  "UserID",
+	AddHook: func(model *structs.BoilerModel, _ *Resolver, _ string) bool {
+		return model.Name == "Post"
+	},
+}
+
+// TestEndToEnd generates models with sqlboiler for the sqlite schema in testdata/e2e, generates the GraphQL API on top
+// of it and runs testdata/e2e/resolvers/e2e_test.go against it. It needs network access or a filled module cache and
+// sqlboiler and sqlboiler-sqlite3 in the PATH, so it only runs with -e2e.
+func TestEndToEnd(t *testing.T) {
+	if !*e2e {
+		t.Skip("run with -e2e to test the generated resolvers against sqlite")
+	}
+	for _, tool := range []string{"sqlboiler", "sqlboiler-sqlite3"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Fatalf("%v is needed in the PATH: %v", tool, err)
+		}
+	}
+	testFile := filepath.Join("resolvers", "e2e_test.go")
+	testContent, err := os.ReadFile(filepath.Join("testdata", "e2e", testFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enterTestModule(t, filepath.Join("testdata", "e2e"))
+
+	// the tests of the generated resolvers are restored after generation since they import the generated models
+	if err := os.Remove(testFile); err != nil {
+		t.Fatal(err)
+	}
+
+	runGo(t, append([]string{"get", "modernc.org/sqlite"}, generatedDependencies...)...)
+	runGo(t, "run", "./migrate", "e2e.db", "schema.sql")
+	run(t, "sqlboiler", "sqlite3", "--config", "sqlboiler.toml")
+
+	boilerCache, err := cache.InitializeBoilerCacheWithLogger(goldenBackend, discardLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := SchemaGet(SchemaConfig{
+		BoilerCache:       boilerCache,
+		GenerateMutations: true,
+		HookChangeField: func(model *SchemaModel, field *SchemaField) {
+			// the scope sets the user of posts
+			if model.Name == "Post" && field.Name == "userId" {
+				field.SkipInput = true
+			}
+		},
+	})
+	// generate posts with backward pagination so both directions are tested
+	schema = strings.Replace(schema, "posts(first: Int!, after: String,", "posts(last: Int!, before: String,", 1)
+	if err := os.WriteFile(goldenSchema, []byte(schema), 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+
+	cfg, err := config.LoadConfig("gqlgen.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := NewModelPlugin().GenerateCode(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authScopes := []*AuthorizationScope{e2eScope}
+	modelCache := cache.InitializeModelCache(cfg, boilerCache, goldenOutput, goldenBackend, goldenFrontend)
+	if err := NewConvertPlugin(modelCache, ConvertPluginConfig{DatabaseDriver: SQLite}).GenerateCode(authScopes); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewResolverPlugin(
+		config.ResolverConfig{Filename: goldenResolver, Package: "resolvers", Type: "Resolver"},
+		goldenOutput,
+		boilerCache,
+		modelCache,
+		ResolverPluginConfig{EnableSoftDeletes: true, AuthorizationScopes: authScopes},
+	).GenerateCode(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(testFile, testContent, 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	runGo(t, "mod", "tidy")
+	runGo(t, "vet", "./...")
+	runGo(t, "test", "./resolvers/")
+}
+
+func run(t *testing.T, name string, args ...string) {
+	t.Helper()
+	if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		t.Fatalf("%v %v failed: %v\n%s", name, strings.Join(args, " "), err, out)
+	}
+}
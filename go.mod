@@ -6,7 +6,7 @@ require (
 	github.com/99designs/gqlgen v0.17.75
 	github.com/aarondl/strmangle v0.0.9
 	github.com/iancoleman/strcase v0.3.0
-	github.com/rs/zerolog v1.34.0
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/vektah/gqlparser/v2 v2.5.28
 	golang.org/x/mod v0.25.0
 	golang.org/x/tools v0.34.0
@@ -15,8 +15,10 @@ require (
 require (
 	github.com/aarondl/inflect v0.0.2 // indirect
 	github.com/agnivade/levenshtein v1.2.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/sosodev/duration v1.3.1 // indirect
 	golang.org/x/sync v0.15.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.26.0 // indirect
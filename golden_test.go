@@ -0,0 +1,405 @@
+package gbgen
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/cache"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
+	"golang.org/x/tools/go/packages"
+)
+
+var (
+	update = flag.Bool("update", false, "update the golden files in testdata/golden")                             //nolint:gochecknoglobals
+	build  = flag.Bool("build", false, "also generate models and resolvers with gqlgen and run go build and vet") //nolint:gochecknoglobals
+)
+
+const (
+	goldenModule   = "example.com/fixture"
+	goldenSchema   = "schema.graphql"
+	goldenResolver = "resolvers/all_generated_resolvers.go"
+)
+
+var (
+	goldenBackend  = structs.Config{Directory: "models/dm", PackageName: "dm"}    //nolint:gochecknoglobals
+	goldenFrontend = structs.Config{Directory: "models/fm", PackageName: "fm"}    //nolint:gochecknoglobals
+	goldenOutput   = structs.Config{Directory: "helpers", PackageName: "helpers"} //nolint:gochecknoglobals
+	goldenConvert  = ConvertPluginConfig{                                         //nolint:gochecknoglobals
+		DatabaseDriver:  PostgreSQL,
+		MaxFilterDepth:  3,
+		Instrumentation: &InstrumentationConfig{},
+	}
+)
+
+const goldenGqlgenConfig = `schema:
+  - schema.graphql
+exec:
+  filename: models/fm/generated.go
+  package: fm
+model:
+  filename: models/fm/generated_models.go
+  package: fm
+models:
+  ConnectionBackwardPagination:
+    model: github.com/web-ridge/utils-go/boilergql/v3.ConnectionBackwardPagination
+  ConnectionForwardPagination:
+    model: github.com/web-ridge/utils-go/boilergql/v3.ConnectionForwardPagination
+  ConnectionPagination:
+    model: github.com/web-ridge/utils-go/boilergql/v3.ConnectionPagination
+  SortDirection:
+    model: github.com/web-ridge/utils-go/boilergql/v3.SortDirection
+`
+
+// TestGoldenSchema compares the schema of the sqlboiler models in testdata/fixture with testdata/golden
+func TestGoldenSchema(t *testing.T) {
+	goldenDir := goldenModuleDir(t)
+	generateGoldenSchema(t)
+	compareGolden(t, goldenSchema, filepath.Join(goldenDir, goldenSchema+".golden"))
+}
+
+// TestGoldenGenerate runs the whole generator on the sqlboiler models in testdata/fixture, compares the helpers and
+// resolvers with testdata/golden and compiles the result. Gqlgen loads the packages of the generated module which
+// needs the dependencies of the generated code, so it only runs with -build. TestGoldenCompile checks the golden files
+// without them.
+func TestGoldenGenerate(t *testing.T) {
+	if !*build {
+		t.Skip("run with -build to generate and compile the helpers and resolvers")
+	}
+	goldenDir := goldenModuleDir(t)
+	boilerCache := generateGoldenSchema(t)
+
+	runGo(t, append([]string{"get"}, generatedDependencies...)...)
+
+	cfg, err := config.LoadConfig("gqlgen.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := NewModelPlugin().GenerateCode(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modelCache := cache.InitializeModelCache(cfg, boilerCache, goldenOutput, goldenBackend, goldenFrontend)
+	if err := NewConvertPlugin(modelCache, goldenConvert).GenerateCode(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewResolverPlugin(
+		config.ResolverConfig{Filename: goldenResolver, Package: "resolvers", Type: "Resolver"},
+		goldenOutput,
+		boilerCache,
+		modelCache,
+		ResolverPluginConfig{Instrumentation: true},
+	).GenerateCode(data); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(goldenOutput.Directory, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range append(files, goldenResolver) {
+		compareGolden(t, file, filepath.Join(goldenDir, file+".golden"))
+	}
+
+	runGo(t, "mod", "tidy")
+	runGo(t, "build", "./...")
+	runGo(t, "vet", "./...")
+}
+
+// goldenModuleDir changes the working directory to a new module with the fixtures and returns the golden directory
+func goldenModuleDir(t *testing.T) string {
+	t.Helper()
+	goldenDir, err := filepath.Abs(filepath.Join("testdata", "golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enterTestModule(t, filepath.Join("testdata", "fixture"))
+	return goldenDir
+}
+
+func generateGoldenSchema(t *testing.T) *cache.BoilerCache {
+	t.Helper()
+	boilerCache, err := cache.InitializeBoilerCacheWithLogger(goldenBackend, discardLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := SchemaGet(SchemaConfig{
+		BoilerCache:         boilerCache,
+		GenerateMutations:   true,
+		GenerateBatchCreate: true,
+		GenerateBatchDelete: true,
+		GenerateBatchUpdate: true,
+	})
+	if err := os.WriteFile(goldenSchema, []byte(schema), 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	return boilerCache
+}
+
+// discardLogger is the logger of the generator in the tests, the caches pass it on to the plugins
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil)) //nolint:gochecknoglobals
+
+// enterTestModule changes the working directory to a new module with the files of dir
+func enterTestModule(t *testing.T, dir string) {
+	t.Helper()
+	module := newTestModule(t)
+	copyDir(t, dir, module)
+	t.Chdir(module)
+}
+
+// newTestModule creates a temporary module with the dependencies of this module and the fixture and the gqlgen config
+func newTestModule(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	goMod, err := os.ReadFile("go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	goMod = bytes.Replace(goMod, []byte("module github.com/web-ridge/gqlgen-sqlboiler/v3"), []byte("module "+goldenModule), 1)
+	goMod = append(goMod, fixtureRequirements...)
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), goMod, 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	goSum, err := os.ReadFile("go.sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtureSum, err := os.ReadFile(filepath.Join("testdata", "fixture.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), append(goSum, fixtureSum...), 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "gqlgen.yml"), []byte(goldenGqlgenConfig), 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// fixtureRequirements are the modules imported by the sqlboiler models in testdata/fixture, their checksums are in
+// testdata/fixture.sum so the models can be loaded without downloading anything
+const fixtureRequirements = `
+require (
+	github.com/aarondl/null/v8 v8.1.3
+	github.com/aarondl/sqlboiler/v4 v4.19.5
+	github.com/friendsofgo/errors v0.9.2
+)
+
+require (
+	github.com/aarondl/randomize v0.0.2 // indirect
+	github.com/gofrs/uuid v4.2.0+incompatible // indirect
+	github.com/spf13/cast v1.5.0 // indirect
+)
+`
+
+// generatedDependencies are imported by the generated code, gqlgen needs them before it can load the models
+var generatedDependencies = []string{ //nolint:gochecknoglobals
+	"github.com/99designs/gqlgen",
+	"github.com/aarondl/null/v8",
+	"github.com/aarondl/sqlboiler/v4",
+	"github.com/friendsofgo/errors",
+	"github.com/web-ridge/utils-go/boilergql/v3",
+}
+
+func compareGolden(t *testing.T, file string, goldenFile string) {
+	t.Helper()
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goldenFile, got, 0o644); err != nil { //nolint:gosec
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Errorf("%v: could not read golden file, run go test -run TestGolden -update: %v", file, err)
+		return
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%v differs from %v, run go test -run TestGolden -update when this is expected\n%v",
+			file, goldenFile, firstDifference(string(want), string(got)))
+	}
+}
+
+// firstDifference returns the first line which differs so failures stay readable for big files
+func firstDifference(want string, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n- %v\n+ %v", i+1, w, g)
+		}
+	}
+	return ""
+}
+
+func copyFile(t *testing.T, from string, to string) {
+	t.Helper()
+	content, err := os.ReadFile(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(to, content, 0o644); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+}
+
+// copyDir copies the files of from to the same relative paths in to
+func copyDir(t *testing.T, from string, to string) {
+	t.Helper()
+	err := filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Join(to, filepath.Dir(rel)), 0o755); err != nil {
+			return err
+		}
+		copyFile(t, path, filepath.Join(to, rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func runGo(t *testing.T, args ...string) {
+	t.Helper()
+	cmd := exec.Command("go", args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go %v failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// unavailableGoldenImports are the package names of the imports of the generated code which can not be loaded without
+// downloading or running gqlgen, the type checker does not report the uses of these packages
+var unavailableGoldenImports = map[string]string{ //nolint:gochecknoglobals
+	goldenModule + "/" + goldenFrontend.Directory: goldenFrontend.PackageName,
+	"github.com/web-ridge/utils-go/boilergql/v3":  "boilergql",
+}
+
+// TestGoldenCompile type checks the golden helpers and resolvers against the sqlboiler models in testdata/fixture, it
+// does not download anything so go test catches generated code which does not compile. The gqlgen models and
+// boilergql are not available offline, run TestGoldenGenerate with -build to build them too.
+func TestGoldenCompile(t *testing.T) {
+	goldenDir := goldenModuleDir(t)
+	fset := token.NewFileSet()
+
+	packageFiles := map[string][]*ast.File{}
+	imports := map[string]bool{}
+	for _, dir := range []string{goldenOutput.Directory, filepath.Dir(goldenResolver)} {
+		goldenFiles, err := filepath.Glob(filepath.Join(goldenDir, dir, "*.go.golden"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the files of the fixture e.g. the Resolver struct are part of the package
+		fixtureFiles, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range append(goldenFiles, fixtureFiles...) {
+			f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+			if err != nil {
+				t.Fatal(err)
+			}
+			packageFiles[dir] = append(packageFiles[dir], f)
+			for _, imp := range f.Imports {
+				importPath, _ := strconv.Unquote(imp.Path.Value)
+				if name, ok := unavailableGoldenImports[importPath]; ok && imp.Name == nil {
+					// the type checker names a package it could not import after the last element of the path
+					imp.Name = ast.NewIdent(name)
+				}
+				if !strings.HasPrefix(importPath, goldenModule+"/") || importPath == goldenModule+"/"+goldenBackend.Directory {
+					imports[importPath] = true
+				}
+			}
+		}
+	}
+
+	var importPaths []string
+	for importPath := range imports {
+		if _, ok := unavailableGoldenImports[importPath]; !ok {
+			importPaths = append(importPaths, importPath)
+		}
+	}
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:       packages.NeedName | packages.NeedTypes | packages.NeedImports | packages.NeedDeps,
+		BuildFlags: []string{"-mod=mod"},
+		Env:        append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off"),
+	}, importPaths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goldenImporter := goldenImporter{}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, pkgErr := range pkg.Errors {
+			t.Errorf("could not load %v: %v", pkg.PkgPath, pkgErr)
+		}
+		goldenImporter[pkg.PkgPath] = pkg.Types
+	})
+
+	// the resolvers import the helpers so the helpers are checked first
+	for _, dir := range []string{goldenOutput.Directory, filepath.Dir(goldenResolver)} {
+		conf := types.Config{
+			Importer: goldenImporter,
+			Error: func(err error) {
+				if !strings.Contains(err.Error(), errUnavailableOffline.Error()) {
+					t.Error(err)
+				}
+			},
+		}
+		importPath := goldenModule + "/" + dir
+		pkg, _ := conf.Check(importPath, fset, packageFiles[dir], nil)
+		goldenImporter[importPath] = pkg
+	}
+}
+
+// goldenImporter returns the loaded packages, the unavailable packages are returned as an error which makes the type
+// checker skip their uses
+type goldenImporter map[string]*types.Package
+
+func (i goldenImporter) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := i[importPath]; ok {
+		return pkg, nil
+	}
+	return nil, errUnavailableOffline
+}
+
+var errUnavailableOffline = errors.New("package is not available offline")
@@ -3,20 +3,18 @@ package gbgen
 import (
 	"fmt"
 	"io/ioutil"
+	"log/slog"
 	"os"
 	"path"
 	"strings"
 
-	"github.com/rs/zerolog/log"
-
 	"golang.org/x/mod/modfile"
 )
 
-func getRootImportPath() string {
+func getRootImportPath(logger *slog.Logger) string {
 	importPath, err := rootImportPath()
 	if err != nil {
-		log.Err(err).Msg(
-			"could not detect root import path %v")
+		logger.Error("could not detect root import path", "error", err)
 		return ""
 	}
 	return importPath
@@ -0,0 +1,24 @@
+// Package logging holds the default logger of the generator. It never changes the global loggers of slog, log or
+// zerolog so the generator can be embedded in other tools without clobbering their logging config, the logger of the
+// generator is passed through the configs of the caches and plugins.
+package logging
+
+import (
+	"log/slog"
+	"os"
+)
+
+var defaultLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})) //nolint:gochecknoglobals
+
+// Default returns the logger of the generator when no logger is configured, debug output to stderr
+func Default() *slog.Logger {
+	return defaultLogger
+}
+
+// OrDefault returns the logger or the default logger when it is nil
+func OrDefault(l *slog.Logger) *slog.Logger {
+	if l != nil {
+		return l
+	}
+	return defaultLogger
+}
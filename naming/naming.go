@@ -0,0 +1,236 @@
+// Package naming holds the naming strategy of the generator which converts the names of sqlboiler models, fields and
+// enums to the names in the GraphQL schema. The strategy is part of the boiler cache so the schema, the model cache and
+// the templates all use the same names and the generated code keeps matching the schema.
+package naming
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aarondl/strmangle"
+	"github.com/iancoleman/strcase"
+)
+
+// FieldCase is the case of the field names in the schema
+type FieldCase int
+
+const (
+	// CamelCase generates fields like firstName and organizationId, the default
+	CamelCase FieldCase = iota
+	// SnakeCase generates fields like first_name and organization_id
+	SnakeCase
+)
+
+// Strategy configures the names in the generated schema, the zero value generates the default names
+type Strategy struct {
+	// Acronyms are written as one word in field names e.g. "API": "api" results in api and apiKey for API and APIKey,
+	// they are added to the default acronyms
+	Acronyms map[string]string
+	// ReplaceDefaultAcronyms uses only Acronyms instead of adding them to the default acronyms, list "ID": "id" to keep
+	// organizationId instead of organizationID
+	ReplaceDefaultAcronyms bool
+	// FieldCase is the case of the fields of the model types, CamelCase by default
+	FieldCase FieldCase
+	// TypePrefix and TypeSuffix are added to the names of all types of a model e.g. with suffix Node the types become
+	// UserNode, UserNodeWhere, UserNodeCreateInput etc.
+	TypePrefix string
+	TypeSuffix string
+	// Renames are the names of models by sqlboiler model and of fields by Model.Field e.g. "User": "Account" and
+	// "User.Email": "emailAddress", a relation is renamed by its relationship e.g. "User.Organization": "company"
+	Renames map[string]string
+	// Plurals are the plurals of words which are inflected wrong e.g. "huis": "huizen" or "news": "news" for a word
+	// without plural, they are used for every name which ends with the word
+	Plurals map[string]string
+	// ListSuffix is added to the plural names of a word without plural e.g. List for newsList and createNewsList so
+	// they differ from the single names, it renames the list queries and batch mutations of these models
+	ListSuffix string
+	// EnumValue returns the name of a value of an enum, by default the value without the enum name as prefix in
+	// screaming snake case e.g. ADMIN for UserRoleAdmin
+	EnumValue func(enumName, valueName string) string
+}
+
+// defaultAcronyms are acronyms which are a whole field name e.g. qr instead of qR
+var defaultAcronyms = map[string]string{ //nolint:gochecknoglobals
+	"QR":  "qr",
+	"KVK": "kvk",
+	"URL": "url",
+	"PDF": "pdf",
+	"FTP": "ftp",
+	"FCM": "fcm",
+}
+
+// defaultWordAcronyms are acronyms which are written as one word inside field names e.g. organizationId
+var defaultWordAcronyms = map[string]string{ //nolint:gochecknoglobals
+	"ID":  "id",
+	"URL": "url",
+}
+
+// TypeName returns the name of the GraphQL type of a sqlboiler model e.g. User
+func (s Strategy) TypeName(modelName string) string {
+	if renamed := s.Renames[modelName]; renamed != "" {
+		modelName = renamed
+	}
+	return s.TypePrefix + modelName + s.TypeSuffix
+}
+
+// FieldName returns the name of the GraphQL field of a sqlboiler field e.g. organizationId for User.OrganizationID
+func (s Strategy) FieldName(modelName, fieldName string) string {
+	if renamed := s.Renames[modelName+"."+fieldName]; renamed != "" {
+		return renamed
+	}
+
+	graphqlName := fieldName
+	acronyms := s.wordAcronyms()
+	if _, ok := s.nameAcronyms()[graphqlName]; ok {
+		return s.fieldCase(graphqlName)
+	}
+	// e.g. OrganizationID to OrganizationId so it becomes organizationId instead of organizationID, longer acronyms
+	// first so they are not replaced partly by a shorter one
+	keys := make([]string, 0, len(acronyms))
+	for acronym := range acronyms {
+		keys = append(keys, acronym)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for _, acronym := range keys {
+		graphqlName = strings.ReplaceAll(graphqlName, acronym, upperFirst(acronyms[acronym]))
+	}
+	return s.fieldCase(graphqlName)
+}
+
+// RelationName returns the name of the GraphQL field of a relationship e.g. organization for User.Organization
+func (s Strategy) RelationName(modelName, relationshipName string) string {
+	if renamed := s.Renames[modelName+"."+relationshipName]; renamed != "" {
+		return renamed
+	}
+	return s.fieldCase(relationshipName)
+}
+
+// EnumValueName returns the name of the GraphQL enum value of a sqlboiler enum value e.g. ADMIN for UserRoleAdmin
+func (s Strategy) EnumValueName(enumName, valueName string) string {
+	if s.EnumValue != nil {
+		return s.EnumValue(enumName, valueName)
+	}
+	return strcase.ToScreamingSnake(strings.TrimPrefix(valueName, enumName))
+}
+
+// Plural returns the plural of a name in the same case e.g. TaskBlockedBy to TaskBlockedBies and person to people, a
+// name without plural gets the ListSuffix of the strategy e.g. newsList
+func (s Strategy) Plural(name string) string {
+	plural := s.WordPlural(name)
+	if s.ListSuffix != "" && plural == name && inflect(name, strmangle.Singular, s.singulars()) == name {
+		return name + s.ListSuffix
+	}
+	return plural
+}
+
+// WordPlural returns the plural of a name like Plural but without the List suffix for a name without plural, it is the
+// plural sqlboiler generates when it uses the same inflections e.g. the Huizen table names for the Huis model
+func (s Strategy) WordPlural(name string) string {
+	return inflect(name, strmangle.Plural, s.plurals())
+}
+
+// Singular returns the singular of a name in the same case e.g. TaskBlockedBies to TaskBlockedBy, People to Person and
+// NewsList to News with the List suffix
+func (s Strategy) Singular(name string) string {
+	if s.ListSuffix != "" {
+		if singular := strings.TrimSuffix(name, s.ListSuffix); singular != name && s.Plural(singular) == name {
+			return singular
+		}
+	}
+	return inflect(name, strmangle.Singular, s.singulars())
+}
+
+// IsPlural returns true if the name is the plural of its singular e.g. users, people and newsList with the List suffix
+func (s Strategy) IsPlural(name string) bool {
+	return name == s.Plural(s.Singular(name))
+}
+
+func (s Strategy) plurals() map[string]string {
+	plurals := map[string]string{}
+	for singular, plural := range s.Plurals {
+		plurals[strings.ToLower(singular)] = strings.ToLower(plural)
+	}
+	return plurals
+}
+
+func (s Strategy) singulars() map[string]string {
+	singulars := map[string]string{}
+	for singular, plural := range s.Plurals {
+		singulars[strings.ToLower(plural)] = strings.ToLower(singular)
+	}
+	return singulars
+}
+
+// inflect inflects the last word of the name with the overrides or else with the inflection of sqlboiler
+func inflect(s string, inflection func(string) string, overrides map[string]string) string {
+	snake := strcase.ToSnake(s)
+	inflected := inflection(snake)
+	if len(overrides) > 0 {
+		words := strings.Split(snake, "_")
+		if override, ok := overrides[words[len(words)-1]]; ok {
+			words[len(words)-1] = override
+			inflected = strings.Join(words, "_")
+		}
+	}
+
+	title := strmangle.TitleCase(inflected)
+	if len(s) > 0 && s[0] == strings.ToLower(s)[0] {
+		a := []rune(title)
+		a[0] = unicode.ToLower(a[0])
+		return string(a)
+	}
+	return title
+}
+
+// wordAcronyms are the acronyms which are written as one word inside field names
+func (s Strategy) wordAcronyms() map[string]string {
+	if s.ReplaceDefaultAcronyms {
+		return s.Acronyms
+	}
+	return mergeAcronyms(defaultWordAcronyms, s.Acronyms)
+}
+
+// nameAcronyms are the acronyms which are written in lower case when they are the whole field name
+func (s Strategy) nameAcronyms() map[string]string {
+	if s.ReplaceDefaultAcronyms {
+		return s.Acronyms
+	}
+	return mergeAcronyms(defaultAcronyms, defaultWordAcronyms, s.Acronyms)
+}
+
+func mergeAcronyms(maps ...map[string]string) map[string]string {
+	acronyms := map[string]string{}
+	for _, m := range maps {
+		for acronym, lower := range m {
+			acronyms[acronym] = lower
+		}
+	}
+	return acronyms
+}
+
+// fieldCase converts the name to the case of the fields, a name which is an acronym is written like the acronym e.g.
+// qr for QR
+func (s Strategy) fieldCase(name string) string {
+	if lower, ok := s.nameAcronyms()[name]; ok {
+		name = lower
+	}
+	if s.FieldCase == SnakeCase {
+		return strcase.ToSnake(name)
+	}
+	return strcase.ToLowerCamel(name)
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	a := []rune(s)
+	a[0] = unicode.ToUpper(a[0])
+	return string(a)
+}
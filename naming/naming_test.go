@@ -0,0 +1,149 @@
+package naming
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		got      func(s Strategy) string
+		expected string
+	}{
+		{name: "type", got: func(s Strategy) string { return s.TypeName("User") }, expected: "User"},
+		{
+			name:     "type with prefix, suffix and rename",
+			strategy: Strategy{TypePrefix: "Api", TypeSuffix: "Node", Renames: map[string]string{"User": "Account"}},
+			got:      func(s Strategy) string { return s.TypeName("User") },
+			expected: "ApiAccountNode",
+		},
+		{name: "field", got: func(s Strategy) string { return s.FieldName("User", "FirstName") }, expected: "firstName"},
+		{name: "id", got: func(s Strategy) string { return s.FieldName("User", "ID") }, expected: "id"},
+		{name: "foreign key", got: func(s Strategy) string { return s.FieldName("User", "OrganizationID") }, expected: "organizationId"},
+		{name: "url", got: func(s Strategy) string { return s.FieldName("User", "AvatarURL") }, expected: "avatarUrl"},
+		{
+			name:     "snake case",
+			strategy: Strategy{FieldCase: SnakeCase},
+			got:      func(s Strategy) string { return s.FieldName("User", "OrganizationID") },
+			expected: "organization_id",
+		},
+		{
+			name:     "acronym",
+			strategy: Strategy{Acronyms: map[string]string{"API": "api"}},
+			got:      func(s Strategy) string { return s.FieldName("User", "APIKey") },
+			expected: "apiKey",
+		},
+		{name: "acronym in a word", got: func(s Strategy) string { return s.FieldName("User", "IDCard") }, expected: "idCard"},
+		{name: "whole acronym", got: func(s Strategy) string { return s.FieldName("User", "QR") }, expected: "qr"},
+		{
+			name:     "replaced default acronyms",
+			strategy: Strategy{Acronyms: map[string]string{"API": "api"}, ReplaceDefaultAcronyms: true},
+			got:      func(s Strategy) string { return s.FieldName("User", "IDCard") + " " + s.FieldName("User", "APIKey") },
+			expected: "idcard apiKey",
+		},
+		{
+			name:     "acronym relation",
+			strategy: Strategy{Acronyms: map[string]string{"KYC": "kyc"}},
+			got:      func(s Strategy) string { return s.RelationName("User", "KYC") },
+			expected: "kyc",
+		},
+		{
+			name:     "renamed field",
+			strategy: Strategy{Renames: map[string]string{"User.Email": "emailAddress"}},
+			got:      func(s Strategy) string { return s.FieldName("User", "Email") },
+			expected: "emailAddress",
+		},
+		{
+			name:     "renamed relation",
+			strategy: Strategy{Renames: map[string]string{"User.Organization": "company"}},
+			got:      func(s Strategy) string { return s.RelationName("User", "Organization") },
+			expected: "company",
+		},
+		{name: "enum value", got: func(s Strategy) string { return s.EnumValueName("UserRole", "UserRoleSuperAdmin") }, expected: "SUPER_ADMIN"},
+		{
+			name:     "enum value strategy",
+			strategy: Strategy{EnumValue: func(enumName, valueName string) string { return strings.ToLower(valueName) }},
+			got:      func(s Strategy) string { return s.EnumValueName("UserRole", "UserRoleAdmin") },
+			expected: "userroleadmin",
+		},
+		{name: "plural", got: func(s Strategy) string { return s.Plural("TaskBlockedBy") }, expected: "TaskBlockedBies"},
+		{name: "plural lower case", got: func(s Strategy) string { return s.Plural("person") }, expected: "people"},
+		{name: "singular", got: func(s Strategy) string { return s.Singular("People") }, expected: "Person"},
+		{name: "plural without plural", got: func(s Strategy) string { return s.Plural("News") }, expected: "News"},
+		{
+			name:     "plural without plural with list suffix",
+			strategy: Strategy{ListSuffix: "List"},
+			got:      func(s Strategy) string { return s.Plural("News") },
+			expected: "NewsList",
+		},
+		{
+			name:     "singular without plural with list suffix",
+			strategy: Strategy{ListSuffix: "List"},
+			got:      func(s Strategy) string { return s.Singular("newsList") },
+			expected: "news",
+		},
+		{
+			name:     "singular of list",
+			strategy: Strategy{ListSuffix: "List"},
+			got:      func(s Strategy) string { return s.Singular("TodoList") },
+			expected: "TodoList",
+		},
+		{
+			name:     "plural override",
+			strategy: Strategy{Plurals: map[string]string{"Status": "Statuses"}},
+			got:      func(s Strategy) string { return s.Plural("OrderStatus") },
+			expected: "OrderStatuses",
+		},
+		{
+			name:     "plural override without plural",
+			strategy: Strategy{Plurals: map[string]string{"huis": "huis"}, ListSuffix: "List"},
+			got:      func(s Strategy) string { return s.Plural("Huis") },
+			expected: "HuisList",
+		},
+		{
+			name:     "word plural",
+			strategy: Strategy{Plurals: map[string]string{"huis": "huizen"}},
+			got:      func(s Strategy) string { return s.WordPlural("Huis") + " " + s.WordPlural("News") },
+			expected: "Huizen News",
+		},
+		{
+			name:     "singular override",
+			strategy: Strategy{Plurals: map[string]string{"status": "statuses"}},
+			got:      func(s Strategy) string { return s.Singular("orderStatuses") },
+			expected: "orderStatus",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got(tt.strategy); got != tt.expected {
+				t.Errorf("expected %v but got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsPlural(t *testing.T) {
+	strategy := Strategy{Plurals: map[string]string{"huis": "huizen"}, ListSuffix: "List"}
+
+	tests := map[string]bool{
+		"users":     true,
+		"user":      false,
+		"people":    true,
+		"data":      true,
+		"news":      false,
+		"newsList":  true,
+		"statuses":  true,
+		"status":    false,
+		"huizen":    true,
+		"huis":      false,
+		"todoList":  false,
+		"todoLists": true,
+	}
+	for name, expected := range tests {
+		if got := strategy.IsPlural(name); got != expected {
+			t.Errorf("IsPlural(%v) should be %v", name, expected)
+		}
+	}
+}
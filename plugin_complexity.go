@@ -0,0 +1,161 @@
+package gbgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	gqlgenTemplates "github.com/99designs/gqlgen/codegen/templates"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/templates"
+)
+
+const defaultComplexityListMultiplier = 10
+
+type ComplexityConfig struct {
+	// Filename of the generated file, defaults to generated_complexity.go in the directory of the resolvers
+	Filename string
+	// ListMultiplier is the expected amount of items in a relation list (e.g. user.posts) which does not have a
+	// first or last argument, defaults to 10
+	ListMultiplier int
+}
+
+type ComplexityBuild struct {
+	PackageName    string
+	Imports        []Import
+	ListMultiplier int
+	Fields         []*ComplexityField
+}
+
+type ComplexityField struct {
+	ObjectName string
+	FieldName  string
+	// Declaration is the signature of the complexity function in the gqlgen ComplexityRoot
+	Declaration string
+	// Result is the go expression which calculates the complexity of the field
+	Result string
+	field  *codegen.Field
+}
+
+func (m *ResolverPlugin) renderComplexityFile(data *codegen.Data, resolverBuild *ResolverBuild) (string, []byte, error) {
+	cfg := m.pluginConfig.Complexity
+
+	fileName := cfg.Filename
+	if fileName == "" {
+		fileName = filepath.Join(filepath.Dir(m.resolverConfig.Filename), "generated_complexity.go")
+	}
+	listMultiplier := cfg.ListMultiplier
+	if listMultiplier <= 0 {
+		listMultiplier = defaultComplexityListMultiplier
+	}
+
+	build := &ComplexityBuild{
+		PackageName:    m.resolverConfig.Package,
+		Imports:        resolverBuild.Imports,
+		ListMultiplier: listMultiplier,
+		Fields:         getComplexityFields(data.Objects),
+	}
+	if err := setComplexityDeclarations(build.Fields, build.PackageName, build.Imports, data.Config); err != nil {
+		m.logger.Error("could not get the complexity signatures", "error", err)
+		return "", nil, err
+	}
+
+	templateName := "generated_complexity.gotpl"
+	source, err := loadTemplate(m.pluginConfig.TemplateDirectory, templateName)
+	if err != nil {
+		m.logger.Error("error when reading "+templateName, "error", err)
+		return "", nil, err
+	}
+
+	content, err := templates.RenderTemplateFile(fileName, templates.Options{
+		Name:        templateName,
+		Template:    source.Template,
+		Blocks:      source.Blocks,
+		PackageName: m.resolverConfig.Package,
+		Data:        build,
+		Naming:      m.ModelCache.Naming,
+	})
+	return fileName, content, err
+}
+
+// getComplexityFields returns the fields which return more than one item, connections are multiplied by the
+// requested page size and relation lists by a fixed multiplier. Other fields use the gqlgen default.
+func getComplexityFields(objects codegen.Objects) []*ComplexityField {
+	var fields []*ComplexityField
+	for _, o := range objects {
+		if o.IsReserved() {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, f := range o.Fields {
+			if f.IsReserved() || seen[f.GoFieldName] {
+				continue
+			}
+			seen[f.GoFieldName] = true
+
+			result := getComplexityResult(f)
+			if result == "" {
+				continue
+			}
+			fields = append(fields, &ComplexityField{
+				ObjectName: gqlgenTemplates.UcFirst(o.Name),
+				FieldName:  f.GoFieldName,
+				Result:     result,
+				field:      f,
+			})
+		}
+	}
+	return fields
+}
+
+func getComplexityResult(f *codegen.Field) string {
+	for _, arg := range f.Args {
+		if arg.Name != "first" && arg.Name != "last" {
+			continue
+		}
+		if arg.TypeReference.IsPtr() {
+			return fmt.Sprintf("(1 + childComplexity) * complexityPageSize(%v)", arg.VarName)
+		}
+		return fmt.Sprintf("(1 + childComplexity) * %v", arg.VarName)
+	}
+	if f.TypeReference.IsSlice() {
+		return "(1 + childComplexity) * complexityListMultiplier"
+	}
+	return ""
+}
+
+// setComplexityDeclarations sets the declarations of the fields to the ComplexitySignature of gqlgen. Gqlgen only
+// resolves the types of the signature while it renders a file, so an empty file is rendered to a temporary directory
+// with the imports of the resolvers reserved and the signatures are read during the rendering.
+func setComplexityDeclarations(fields []*ComplexityField, packageName string, imports []Import, cfg *config.Config) error {
+	dir, err := os.MkdirTemp("", "gqlgen-sqlboiler-complexity")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	return gqlgenTemplates.Render(gqlgenTemplates.Options{
+		PackageName: packageName,
+		Template:    "{{ setDeclarations }}",
+		Filename:    filepath.Join(dir, "complexity.go"),
+		Packages:    cfg.Packages,
+		Funcs: template.FuncMap{
+			"setDeclarations": func() string {
+				for _, imp := range imports {
+					if imp.Alias != "." {
+						// an import path which is already reserved keeps its first alias
+						_, _ = gqlgenTemplates.CurrentImports.Reserve(imp.ImportPath, imp.Alias)
+					}
+				}
+				for _, field := range fields {
+					field.Declaration = field.field.ComplexitySignature()
+				}
+				return ""
+			},
+		},
+	})
+}
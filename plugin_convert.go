@@ -1,13 +1,14 @@
 package gbgen
 
 import (
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"log/slog"
 	"os"
 	"path"
-	"path/filepath"
 	"regexp"
-	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
 
@@ -15,8 +16,7 @@ import (
 
 	"github.com/web-ridge/gqlgen-sqlboiler/v3/customization"
 
-	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/logging"
 	"github.com/web-ridge/gqlgen-sqlboiler/v3/templates"
 )
 
@@ -24,11 +24,6 @@ var pathRegex *regexp.Regexp //nolint:gochecknoglobals
 
 func init() { //nolint:gochecknoinits
 	pathRegex = regexp.MustCompile(`src/(.*)`)
-
-	// Default level for this example is info, unless debug flag is present
-	zerolog.SetGlobalLevel(zerolog.DebugLevel)
-
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 }
 
 type Import struct {
@@ -46,6 +41,124 @@ type ConvertTemplateData struct {
 	Enums               []*structs.Enum
 	Scalars             []string
 	AuthorizationScopes []*AuthorizationScope
+	// ModelCache contains everything the generator knows about the models e.g. for a FileGenerator
+	ModelCache *cache.ModelCache
+	// UserDefinedFunctions are the functions of the helpers package which are not generated
+	UserDefinedFunctions []customization.Function
+}
+
+// HasHook returns true if the user defined a hook e.g. AfterUserToGraphQL in the helpers package
+func (t ConvertTemplateData) HasHook(name string) bool {
+	for _, fn := range t.UserDefinedFunctions {
+		if fn.Key() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hookErrors checks the signatures of the hooks which are defined by the user so a mismatch is reported with the
+// position of the hook instead of as a compile error in the generated code
+func (t ConvertTemplateData) hookErrors() error {
+	expected := map[string]customization.Function{}
+	for _, model := range t.Models {
+		if model.BoilerModel == nil {
+			continue
+		}
+		if model.IsInput {
+			expected["Before"+model.Name+"ToBoiler"] = hookSignature(t.Frontend, model.Name, t.Backend, model.BoilerModel.Name)
+		} else if model.IsNormal {
+			expected["After"+model.Name+"ToGraphQL"] = hookSignature(t.Backend, model.BoilerModel.Name, t.Frontend, model.Name)
+		}
+	}
+
+	var errs []error
+	for _, fn := range t.UserDefinedFunctions {
+		hook, ok := expected[fn.Key()]
+		if ok && fn.NormalizedSignature != hook.NormalizedSignature {
+			errs = append(errs, fmt.Errorf("hook %v in %v has signature %v but should be %v",
+				fn.Key(), fn.Position, fn.Signature, hook.Signature))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hookSignature returns the signature of a hook which receives the converted model, the Directory of the configs is
+// the import path of the package
+func hookSignature(from structs.Config, fromName string, to structs.Config, toName string) customization.Function {
+	return customization.Function{
+		Signature: fmt.Sprintf("func(context.Context, *%v.%v, *%v.%v)",
+			from.PackageName, fromName, to.PackageName, toName),
+		NormalizedSignature: fmt.Sprintf("func(context.Context, *%v.%v, *%v.%v)",
+			from.Directory, fromName, to.Directory, toName),
+	}
+}
+
+// enumErrors returns the values of enums with database values which can not be converted in one of the directions,
+// the generated converters would return an empty value for these
+func (t ConvertTemplateData) enumErrors() error {
+	var errs []error
+	for _, enum := range t.Enums {
+		if !enum.HasBoilerEnum {
+			continue
+		}
+		converted := map[*structs.BoilerEnumValue]bool{}
+		for _, value := range enum.Values {
+			if value.BoilerEnumValue == nil {
+				errs = append(errs, fmt.Errorf("enum value %v.%v has no database value", enum.Name, value.Name))
+				continue
+			}
+			converted[value.BoilerEnumValue] = true
+		}
+		for _, value := range enum.BoilerEnum.Values {
+			if !converted[value] {
+				errs = append(errs, fmt.Errorf("database value %v of enum %v has no GraphQL value", value.Name, enum.Name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// paginationErrors returns an error when a default page size in the schema differs from the Pagination of the
+// plugin, the schema gets its defaults from the Pagination of the SchemaConfig so both have to use the same config
+func (t ConvertTemplateData) paginationErrors() error {
+	if t.ModelCache == nil || t.ModelCache.Schema == nil || t.ModelCache.Schema.Query == nil {
+		return nil
+	}
+	var errs []error
+	for _, field := range t.ModelCache.Schema.Query.Fields {
+		modelName, ok := strings.CutSuffix(field.Type.Name(), "Connection")
+		if !ok {
+			continue
+		}
+		model := findModelOrEmpty(t.Models, modelName)
+		if model.BoilerModel == nil {
+			continue
+		}
+		for _, argument := range field.Arguments {
+			if (argument.Name != "first" && argument.Name != "last") || argument.DefaultValue == nil {
+				continue
+			}
+			pageSize := t.PluginConfig.Pagination.For(model.BoilerModel.Name).Default
+			if argument.DefaultValue.Raw != strconv.Itoa(pageSize) {
+				errs = append(errs, fmt.Errorf(
+					"default page size of %v(%v) is %v in the schema but %v in the ConvertPluginConfig, use the same PaginationConfig in the SchemaConfig",
+					field.Name, argument.Name, argument.DefaultValue.Raw, pageSize))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FileGenerator adds a file to the generated helpers e.g. a REST handler or an export function for every model.
+// The file is rendered like the other helpers so functions which are defined by the user in the helpers package are
+// renamed to original{{Name}} in the generated file.
+type FileGenerator interface {
+	// Name of the generated file in the output directory of the helpers e.g. generated_rest.go
+	Name() string
+	// Generate returns the template of the file, the data defaults to the ConvertTemplateData. PackageName and
+	// UserDefinedFunctions are set by the ConvertPlugin.
+	Generate(data *ConvertTemplateData) (templates.Options, error)
 }
 
 func (t ConvertTemplateData) Imports() []Import {
@@ -70,14 +183,22 @@ func (t ConvertTemplateData) Imports() []Import {
 			seen[scope.ImportAlias] = true
 		}
 	}
+	if codec := t.PluginConfig.GlobalIDCodec; codec != nil && codec.TypeName != "" && !seen[codec.ImportAlias] {
+		imports = append(imports, Import{
+			Alias:      codec.ImportAlias,
+			ImportPath: codec.ImportPath,
+		})
+	}
 	return imports
 }
 
 func NewConvertPlugin(modelCache *cache.ModelCache, pluginConfig ConvertPluginConfig) *ConvertPlugin {
+	logger := pluginLogger(pluginConfig.Logger, modelCache)
 	return &ConvertPlugin{
 		ModelCache:     modelCache,
 		PluginConfig:   pluginConfig,
-		rootImportPath: getRootImportPath(),
+		rootImportPath: getRootImportPath(logger),
+		logger:         logger,
 	}
 }
 
@@ -86,6 +207,15 @@ type ConvertPlugin struct {
 	ModelCache     *cache.ModelCache
 	PluginConfig   ConvertPluginConfig
 	rootImportPath string
+	logger         *slog.Logger
+}
+
+// pluginLogger returns the logger of the plugin config, the logger of the model cache or else the default logger
+func pluginLogger(logger *slog.Logger, modelCache *cache.ModelCache) *slog.Logger {
+	if logger == nil && modelCache != nil {
+		logger = modelCache.Logger
+	}
+	return logging.OrDefault(logger)
 }
 
 // DatabaseDriver defines which data syntax to use for some of the converts
@@ -96,10 +226,94 @@ const (
 	MySQL DatabaseDriver = "mysql"
 	// PostgreSQL is the default
 	PostgreSQL DatabaseDriver = "postgres"
+	// SQLite expects errors with a Code() int method like the errors of modernc.org/sqlite
+	SQLite DatabaseDriver = "sqlite3"
 )
 
 type ConvertPluginConfig struct {
 	DatabaseDriver DatabaseDriver
+	// MaxFilterDepth limits how deep relation, or and and filters can be nested inside a {{Model}}Where.
+	// Every nested level results in an EXISTS subquery so this protects the database against expensive filters.
+	// 0 means unlimited
+	MaxFilterDepth int
+	// Pagination configures the default and maximum page size of the generated connections, use the same config
+	// in the SchemaConfig so the first argument becomes optional
+	Pagination PaginationConfig
+	// Logger is the logger of the plugin, the logger of the model cache when nil
+	Logger *slog.Logger
+	// Instrumentation wraps the generated CRUD helpers in an operation of the Instrumentation, nil disables it
+	Instrumentation *InstrumentationConfig
+	// GlobalIDCodec converts the global ids of all converts, filters, cursors and node resolvers, nil uses the
+	// generated DashGlobalIDCodec. Ids which clients already have can not be decoded anymore when it changes.
+	GlobalIDCodec *GlobalIDCodecConfig
+	// DryRun compares the generated files with the files on disk instead of writing them
+	DryRun *templates.DryRun
+	// TemplateDirectory contains templates which replace the embedded templates with the same name,
+	// <name>.blocks.gotpl files which replace {{block}} blocks of a template and extra templates which are rendered
+	// next to the generated helpers
+	TemplateDirectory string
+	// FileGenerators add custom files to the generated helpers
+	FileGenerators []FileGenerator
+	// OverrideStubs are generated functions e.g. UserToGraphQL for which a convert_override_*.go file is created once,
+	// the stub calls the generated function under its new name original{{Name}}
+	OverrideStubs []string
+	// AbortOnError returns the first error instead of logging it and generating the other files, nothing is written
+	// when a template could not be rendered or one of the files could not be written
+	AbortOnError bool
+}
+
+type InstrumentationConfig struct {
+	// OpenTelemetry generates NewOpenTelemetryInstrumentation, your module needs go.opentelemetry.io/otel for this
+	OpenTelemetry bool
+}
+
+type GlobalIDCodecConfig struct {
+	// Relay uses the generated RelayGlobalIDCodec which encodes the ids with base64
+	Relay bool
+	// ImportPath, ImportAlias and TypeName refer to your own implementation of the generated GlobalIDCodec interface,
+	// its zero value is used. The package can not import the helpers.
+	ImportPath  string
+	ImportAlias string
+	TypeName    string
+}
+
+const defaultPageSize = 10
+
+type PaginationConfig struct {
+	// DefaultPageSize is used when no first or last is requested, defaults to 10
+	DefaultPageSize int
+	// MaxPageSize is the maximum first or last a client can request, 0 means unlimited
+	MaxPageSize int
+	// Models overrides the page sizes per model, the key is the model name e.g. User
+	Models map[string]PageSize
+}
+
+type PageSize struct {
+	Default int
+	Max     int
+}
+
+// For returns the page sizes of a model, values which are not overridden fall back to the global config
+func (c PaginationConfig) For(modelName string) PageSize {
+	pageSize := PageSize{
+		Default: c.DefaultPageSize,
+		Max:     c.MaxPageSize,
+	}
+	if override, ok := c.Models[modelName]; ok {
+		if override.Default > 0 {
+			pageSize.Default = override.Default
+		}
+		if override.Max > 0 {
+			pageSize.Max = override.Max
+		}
+	}
+	if pageSize.Default <= 0 {
+		pageSize.Default = defaultPageSize
+	}
+	if pageSize.Max > 0 && pageSize.Default > pageSize.Max {
+		pageSize.Default = pageSize.Max
+	}
+	return pageSize
 }
 
 func (m *ConvertPlugin) GenerateCode(authScopes []*AuthorizationScope) error {
@@ -119,10 +333,13 @@ func (m *ConvertPlugin) GenerateCode(authScopes []*AuthorizationScope) error {
 		Enums:               m.ModelCache.Enums,
 		Scalars:             m.ModelCache.Scalars,
 		AuthorizationScopes: authScopes,
+		ModelCache:          m.ModelCache,
 	}
 
-	if err := os.MkdirAll(m.ModelCache.Output.Directory, os.ModePerm); err != nil {
-		log.Error().Err(err).Str("directory", m.ModelCache.Output.Directory).Msg("could not create directories")
+	if m.PluginConfig.DryRun == nil {
+		if err := os.MkdirAll(m.ModelCache.Output.Directory, os.ModePerm); err != nil {
+			m.logger.Error("could not create directories", "error", err, "directory", m.ModelCache.Output.Directory)
+		}
 	}
 
 	if m.PluginConfig.DatabaseDriver == "" {
@@ -130,62 +347,255 @@ func (m *ConvertPlugin) GenerateCode(authScopes []*AuthorizationScope) error {
 	}
 
 	if len(m.ModelCache.Models) == 0 {
-		log.Warn().Msg("no structs found in graphql so skipping generation")
+		m.logger.Warn("no structs found in graphql so skipping generation")
 		return nil
 	}
 
+	// unmapped enum values are always an error since the generated converters would silently return empty values
+	if err := data.enumErrors(); err != nil {
+		return err
+	}
+
+	if err := data.paginationErrors(); err != nil {
+		return err
+	}
+
 	filesToGenerate := []string{
 		"generated_convert.go",
 		"generated_convert_batch.go",
 		"generated_convert_input.go",
 		"generated_crud.go",
+		"generated_errors.go",
 		"generated_filter.go",
+		"generated_global_id.go",
+		"generated_instrumentation.go",
 		"generated_preload.go",
 		"generated_sort.go",
 	}
+	if m.PluginConfig.Instrumentation != nil && m.PluginConfig.Instrumentation.OpenTelemetry {
+		filesToGenerate = append(filesToGenerate, "generated_instrumentation_otel.go")
+	}
+	extraTemplateNames, err := extraTemplates(m.PluginConfig.TemplateDirectory)
+	if err != nil {
+		return err
+	}
+	for _, name := range extraTemplateNames {
+		filesToGenerate = append(filesToGenerate, strings.TrimSuffix(name, "tpl"))
+	}
 
 	// We get all function names from helper repository to check if any customizations are available
 	// we ignore the files we generated by this plugin
-	userDefinedFunctions, err := customization.GetFunctionNamesFromDir(m.ModelCache.Output.PackageName, filesToGenerate)
+	generatedFiles := append([]string{}, filesToGenerate...)
+	for _, generator := range m.PluginConfig.FileGenerators {
+		generatedFiles = append(generatedFiles, generator.Name())
+	}
+	userDefinedFunctions, err := customization.GetFunctionsFromDir(
+		m.ModelCache.Output.Directory, m.ModelCache.Output.PackageName, generatedFiles)
 	if err != nil {
-		log.Err(err).Msg("could not parse user defined functions")
+		m.logger.Error("could not parse user defined functions", "error", err)
 	}
 
+	data.UserDefinedFunctions = userDefinedFunctions
+	if err := data.hookErrors(); err != nil {
+		if m.PluginConfig.AbortOnError {
+			return err
+		}
+		m.logger.Error("invalid convert hooks", "error", err)
+	}
+
+	stubs := pendingOverrideStubs(m.PluginConfig.OverrideStubs, userDefinedFunctions, m.logger)
+
+	// everything is rendered before writing so a broken template does not leave a mix of old and new files
+	var rendered []renderedFile
 	for _, fn := range filesToGenerate {
-		m.generateFile(data, fn, userDefinedFunctions)
+		content, err := m.renderFile(data, fn, userDefinedFunctions)
+		if err == nil && len(stubs) > 0 {
+			var stubFiles []renderedFile
+			stubFiles, stubs, userDefinedFunctions, err = m.renderOverrideStubs(fn, content, stubs, userDefinedFunctions)
+			rendered = append(rendered, stubFiles...)
+			if err == nil && len(stubFiles) > 0 {
+				// render again so the generated functions are renamed to original{{Name}}
+				content, err = m.renderFile(data, fn, userDefinedFunctions)
+			}
+		}
+		if err != nil {
+			if m.PluginConfig.AbortOnError {
+				return err
+			}
+			m.logger.Error("error while rendering "+fn+"tpl", "error", err)
+			continue
+		}
+		rendered = append(rendered, renderedFile{fileName: fn, content: content})
 	}
-	return nil
+	if len(stubs) > 0 {
+		err := fmt.Errorf("could not create override stubs, %v are not generated", strings.Join(stubs, ", "))
+		if m.PluginConfig.AbortOnError {
+			return err
+		}
+		m.logger.Error("error while rendering override stubs", "error", err)
+	}
+	for _, generator := range m.PluginConfig.FileGenerators {
+		content, err := m.renderGeneratorFile(data, generator, userDefinedFunctions)
+		if err != nil {
+			if m.PluginConfig.AbortOnError {
+				return err
+			}
+			m.logger.Error("error while rendering "+generator.Name(), "error", err)
+			continue
+		}
+		rendered = append(rendered, renderedFile{fileName: generator.Name(), content: content})
+	}
+
+	return m.writeFiles(rendered)
+}
+
+type renderedFile struct {
+	fileName string
+	content  []byte
 }
 
-func (m *ConvertPlugin) generateFile(data *ConvertTemplateData, fileName string, userDefinedFunctions []string) {
+func (m *ConvertPlugin) renderFile(data *ConvertTemplateData, fileName string, userDefinedFunctions []customization.Function) ([]byte, error) {
 	templateName := fileName + "tpl"
-	// log.Debug().Msg("[convert] render " + templateName)
 
-	templateContent, err := getTemplateContent(templateName)
+	source, err := loadTemplate(m.PluginConfig.TemplateDirectory, templateName)
 	if err != nil {
-		log.Err(err).Msg("error when reading " + templateName)
+		return nil, err
 	}
 
-	if renderError := templates.WriteTemplateFile(
+	return templates.RenderTemplateFile(
 		m.ModelCache.Output.Directory+"/"+fileName,
 		templates.Options{
-			Template:             templateContent,
+			Name:                 templateName,
+			Template:             source.Template,
+			Blocks:               source.Blocks,
 			PackageName:          m.ModelCache.Output.PackageName,
 			Data:                 data,
 			UserDefinedFunctions: userDefinedFunctions,
-		}); renderError != nil {
-		log.Err(renderError).Msg("error while rendering " + templateName)
+			Naming:               m.ModelCache.Naming,
+		})
+}
+
+// renderOverrideStubs creates the stubs of the generated file, it returns the stubs which are still pending and the
+// user defined functions including the new stubs
+func (m *ConvertPlugin) renderOverrideStubs(
+	fileName string,
+	content []byte,
+	pending []string,
+	userDefinedFunctions []customization.Function,
+) ([]renderedFile, []string, []customization.Function, error) {
+	stubs, err := customization.OverrideStubs(fileName, content, "convert", pending, false)
+	if err != nil {
+		return nil, pending, userDefinedFunctions, err
 	}
-	log.Debug().Msg("[convert] generated " + templateName)
+	var files []renderedFile
+	for _, stub := range stubs {
+		// the stub is owned by the user so an existing file is never replaced
+		if _, err := os.Stat(m.ModelCache.Output.Directory + "/" + stub.FileName); err == nil {
+			return nil, pending, userDefinedFunctions, fmt.Errorf(
+				"%v already exists, remove it to create the override stub again", stub.FileName)
+		}
+		files = append(files, renderedFile{fileName: stub.FileName, content: stub.Content})
+		pending = removeString(pending, stub.Function.Key())
+		userDefinedFunctions = append(userDefinedFunctions, stub.Function)
+	}
+	return files, pending, userDefinedFunctions, nil
+}
+
+// pendingOverrideStubs returns the stubs which do not have a user defined function yet
+func pendingOverrideStubs(stubs []string, userDefinedFunctions []customization.Function, logger *slog.Logger) []string {
+	var pending []string
+	for _, stub := range stubs {
+		overridden := false
+		for _, fn := range userDefinedFunctions {
+			if fn.Key() == stub {
+				overridden = true
+				break
+			}
+		}
+		if overridden {
+			logger.Debug("[convert] " + stub + " is already overridden")
+			continue
+		}
+		pending = append(pending, stub)
+	}
+	return pending
 }
 
-func getTemplateContent(filename string) (string, error) {
-	// load path relative to calling source file
-	_, callerFile, _, _ := runtime.Caller(1) //nolint:dogsled
-	rootDir := filepath.Dir(callerFile)
-	content, err := ioutil.ReadFile(path.Join(rootDir, "template_files", filename))
+func removeString(values []string, value string) []string {
+	var a []string
+	for _, v := range values {
+		if v != value {
+			a = append(a, v)
+		}
+	}
+	return a
+}
+
+func (m *ConvertPlugin) renderGeneratorFile(
+	data *ConvertTemplateData,
+	generator FileGenerator,
+	userDefinedFunctions []customization.Function,
+) ([]byte, error) {
+	options, err := generator.Generate(data)
 	if err != nil {
-		return "", fmt.Errorf("could not read template file: %v", err)
+		return nil, fmt.Errorf("could not generate %v: %w", generator.Name(), err)
+	}
+	if options.Name == "" {
+		options.Name = generator.Name()
+	}
+	if options.Data == nil {
+		options.Data = data
 	}
-	return string(content), nil
+	options.PackageName = m.ModelCache.Output.PackageName
+	options.UserDefinedFunctions = userDefinedFunctions
+	options.Naming = m.ModelCache.Naming
+
+	return templates.RenderTemplateFile(m.ModelCache.Output.Directory+"/"+generator.Name(), options)
+}
+
+// writeFiles stages every file before replacing any of them, with AbortOnError the staged files are removed when one
+// of them could not be staged so the output directory is not left with a mix of old and new files
+func (m *ConvertPlugin) writeFiles(files []renderedFile) error {
+	if m.PluginConfig.DryRun != nil {
+		for _, file := range files {
+			fileName := m.ModelCache.Output.Directory + "/" + file.fileName
+			if err := m.PluginConfig.DryRun.Compare(fileName, file.content); err != nil {
+				if m.PluginConfig.AbortOnError {
+					return err
+				}
+				m.logger.Error("error while writing "+file.fileName, "error", err)
+			}
+		}
+		return nil
+	}
+
+	staged := make([]*templates.StagedFile, 0, len(files))
+	defer func() {
+		// no-op for the files which are renamed
+		for _, file := range staged {
+			file.Discard()
+		}
+	}()
+	for _, file := range files {
+		stagedFile, err := templates.StageFile(m.ModelCache.Output.Directory+"/"+file.fileName, file.content)
+		if err != nil {
+			if m.PluginConfig.AbortOnError {
+				return err
+			}
+			m.logger.Error("error while writing "+file.fileName, "error", err)
+			continue
+		}
+		staged = append(staged, stagedFile)
+	}
+	for _, file := range staged {
+		if err := file.Commit(); err != nil {
+			if m.PluginConfig.AbortOnError {
+				return err
+			}
+			m.logger.Error("error while writing "+file.FileName(), "error", err)
+			continue
+		}
+		m.logger.Debug("[convert] generated " + file.FileName())
+	}
+	return nil
 }
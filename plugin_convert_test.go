@@ -0,0 +1,247 @@
+package gbgen
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/cache"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/customization"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
+)
+
+func TestPaginationConfig_For(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    PaginationConfig
+		modelName string
+		want      PageSize
+	}{
+		{
+			name:      "defaults",
+			config:    PaginationConfig{},
+			modelName: "User",
+			want:      PageSize{Default: 10, Max: 0},
+		},
+		{
+			name:      "global config",
+			config:    PaginationConfig{DefaultPageSize: 20, MaxPageSize: 100},
+			modelName: "User",
+			want:      PageSize{Default: 20, Max: 100},
+		},
+		{
+			name: "model override",
+			config: PaginationConfig{
+				DefaultPageSize: 20,
+				MaxPageSize:     100,
+				Models:          map[string]PageSize{"User": {Max: 500}},
+			},
+			modelName: "User",
+			want:      PageSize{Default: 20, Max: 500},
+		},
+		{
+			name:      "default is never larger than max",
+			config:    PaginationConfig{MaxPageSize: 5},
+			modelName: "User",
+			want:      PageSize{Default: 5, Max: 5},
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.For(tt.modelName); got != tt.want {
+				t.Errorf("For() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertTemplateData_hookErrors(t *testing.T) {
+	boilerModel := &structs.BoilerModel{Name: "User"}
+	data := ConvertTemplateData{
+		Backend:  structs.Config{Directory: "example.com/models/dm", PackageName: "dm"},
+		Frontend: structs.Config{Directory: "example.com/models/fm", PackageName: "fm"},
+		Models: []*structs.Model{
+			{Name: "User", IsNormal: true, BoilerModel: boilerModel},
+			{Name: "UserCreateInput", IsInput: true, BoilerModel: boilerModel},
+		},
+	}
+	tests := []struct {
+		name     string
+		function customization.Function
+		err      string
+	}{
+		{
+			name: "after to graphql",
+			function: customization.Function{
+				Name:                "AfterUserToGraphQL",
+				Signature:           "func(context.Context, *dm.User, *fm.User)",
+				NormalizedSignature: "func(context.Context, *example.com/models/dm.User, *example.com/models/fm.User)",
+			},
+		},
+		{
+			name: "before to boiler with an alias",
+			function: customization.Function{
+				Name:                "BeforeUserCreateInputToBoiler",
+				Signature:           "func(context.Context, *graphql.UserCreateInput, *models.User)",
+				NormalizedSignature: "func(context.Context, *example.com/models/fm.UserCreateInput, *example.com/models/dm.User)",
+			},
+		},
+		{
+			name:     "method with the name of a hook",
+			function: customization.Function{Receiver: "hooks", Name: "AfterUserToGraphQL", Signature: "func()"},
+		},
+		{
+			name: "mismatch",
+			function: customization.Function{
+				Name:                "AfterUserToGraphQL",
+				Signature:           "func(*dm.User, *fm.User)",
+				NormalizedSignature: "func(*example.com/models/dm.User, *example.com/models/fm.User)",
+				Position:            "hooks.go:3",
+			},
+			err: "hook AfterUserToGraphQL in hooks.go:3 has signature func(*dm.User, *fm.User) but should be " +
+				"func(context.Context, *dm.User, *fm.User)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data.UserDefinedFunctions = []customization.Function{tt.function}
+			err := data.hookErrors()
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !data.HasHook(tt.function.Name) && tt.function.Receiver == "" {
+					t.Errorf("expected hook %v", tt.function.Name)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestConvertTemplateData_enumErrors(t *testing.T) {
+	admin := &structs.BoilerEnumValue{Name: "UserRoleAdmin"}
+	viewer := &structs.BoilerEnumValue{Name: "UserRoleViewer"}
+	boilerEnum := &structs.BoilerEnum{Name: "UserRole", Values: []*structs.BoilerEnumValue{admin, viewer}}
+	tests := []struct {
+		name   string
+		values []*structs.EnumValue
+		err    string
+	}{
+		{
+			name:   "all values mapped",
+			values: []*structs.EnumValue{{Name: "ADMIN", BoilerEnumValue: admin}, {Name: "VIEWER", BoilerEnumValue: viewer}},
+		},
+		{
+			name:   "graphql value without database value",
+			values: []*structs.EnumValue{{Name: "ADMIN", BoilerEnumValue: admin}, {Name: "VIEWER", BoilerEnumValue: viewer}, {Name: "EDITOR"}},
+			err:    "enum value UserRole.EDITOR has no database value",
+		},
+		{
+			name:   "database value without graphql value",
+			values: []*structs.EnumValue{{Name: "ADMIN", BoilerEnumValue: admin}},
+			err:    "database value UserRoleViewer of enum UserRole has no GraphQL value",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			data := ConvertTemplateData{Enums: []*structs.Enum{
+				{Name: "UserRole", HasBoilerEnum: true, BoilerEnum: boilerEnum, Values: tt.values},
+				// enums which are not in the database have no database values to check
+				{Name: "Color", Values: []*structs.EnumValue{{Name: "RED"}}},
+			}}
+			err := data.enumErrors()
+			if tt.err == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestConvertTemplateData_paginationErrors(t *testing.T) {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: `
+type User { id: ID! }
+type UserConnection { edges: [User!]! }
+type Query {
+	users(first: Int = 20, after: String): UserConnection!
+	accounts(first: Int!): UserConnection!
+}`})
+	models := []*structs.Model{{Name: "User", BoilerModel: &structs.BoilerModel{Name: "User"}}}
+	tests := []struct {
+		name       string
+		pagination PaginationConfig
+		err        string
+	}{
+		{name: "same default", pagination: PaginationConfig{DefaultPageSize: 20}},
+		{name: "same default of model", pagination: PaginationConfig{Models: map[string]PageSize{"User": {Default: 20}}}},
+		{
+			name:       "other default",
+			pagination: PaginationConfig{},
+			err:        "default page size of users(first) is 20 in the schema but 10 in the ConvertPluginConfig",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			data := ConvertTemplateData{
+				Models:       models,
+				PluginConfig: ConvertPluginConfig{Pagination: tt.pagination},
+				ModelCache:   &cache.ModelCache{Models: models, Schema: schema},
+			}
+			err := data.paginationErrors()
+			if tt.err == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestConvertPlugin_writeFilesAbortOnError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "convert.go"), []byte("package helpers\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// the directory of the second file is a file so it can not be staged
+	if err := os.WriteFile(filepath.Join(dir, "sub"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &ConvertPlugin{
+		ModelCache:   &cache.ModelCache{Output: structs.Config{Directory: dir}},
+		PluginConfig: ConvertPluginConfig{AbortOnError: true},
+		logger:       slog.Default(),
+	}
+	err := m.writeFiles([]renderedFile{
+		{fileName: "convert.go", content: []byte("package helpers\n\n// new\n")},
+		{fileName: "sub/custom.go", content: []byte("package helpers\n")},
+	})
+	if err == nil {
+		t.Fatal("expected an error when a file could not be staged")
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(dir, "convert.go")); string(content) != "package helpers\n" {
+		t.Errorf("expected convert.go not to be replaced, got %q", content)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected the staged files to be removed, got %v", entries)
+	}
+}
@@ -2,31 +2,36 @@ package gbgen
 
 import (
 	"fmt"
+	"log/slog"
+	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
 
 	"github.com/web-ridge/gqlgen-sqlboiler/v3/cache"
 	"github.com/web-ridge/gqlgen-sqlboiler/v3/customization"
-
-	"github.com/rs/zerolog/log"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/naming"
 
 	"github.com/99designs/gqlgen/codegen"
 	"github.com/99designs/gqlgen/codegen/config"
+	gqlgenTemplates "github.com/99designs/gqlgen/codegen/templates"
 	"github.com/iancoleman/strcase"
 	"github.com/web-ridge/gqlgen-sqlboiler/v3/templates"
 )
 
 func NewResolverPlugin(resolverConfig config.ResolverConfig, output structs.Config, boilerCache *cache.BoilerCache, modelCache *cache.ModelCache, resolverPluginConfig ResolverPluginConfig) *ResolverPlugin {
+	logger := pluginLogger(resolverPluginConfig.Logger, modelCache)
 	return &ResolverPlugin{
 		resolverConfig: resolverConfig,
 		output:         output,
 		BoilerCache:    boilerCache,
 		ModelCache:     modelCache,
 		pluginConfig:   resolverPluginConfig,
-		rootImportPath: getRootImportPath(),
+		rootImportPath: getRootImportPath(logger),
+		logger:         logger,
 	}
 }
 
@@ -41,6 +46,32 @@ type AuthorizationScope struct {
 type ResolverPluginConfig struct {
 	EnableSoftDeletes   bool
 	AuthorizationScopes []*AuthorizationScope
+	// Complexity generates a gqlgen ComplexityRoot and a depth limit extension next to the resolvers
+	// so nested relations and big pages can be limited, nil skips generation
+	Complexity *ComplexityConfig
+	// ErrorPresenter is called by the generated ErrorPresenter before the generated errors are presented,
+	// the hook can return nil to fall back to the generated presenter
+	ErrorPresenter *ErrorPresenterHook
+	// Logger is the logger of the plugin, the logger of the model cache when nil
+	Logger *slog.Logger
+	// Instrumentation wraps every generated resolver in an operation of the instrumentation field of the resolver
+	Instrumentation bool
+	// DryRun compares the generated files with the files on disk instead of writing them
+	DryRun *templates.DryRun
+	// TemplateDirectory contains templates which replace the embedded templates with the same name and
+	// <name>.blocks.gotpl files which replace {{block}} blocks of a template e.g. the updateResolver block of
+	// generated_resolver.gotpl
+	TemplateDirectory string
+	// OverrideStubs are generated resolvers e.g. mutationResolver.CreateUser which are moved once to a
+	// resolver_override_*.go file so you can change them, the resolver is not generated anymore afterwards
+	OverrideStubs []string
+}
+
+// ErrorPresenterHook points to a func(ctx context.Context, err error) *gqlerror.Error in your own code
+type ErrorPresenterHook struct {
+	ImportPath   string
+	ImportAlias  string
+	FunctionName string
 }
 
 type ResolverPlugin struct {
@@ -50,6 +81,7 @@ type ResolverPlugin struct {
 	output         structs.Config
 	pluginConfig   ResolverPluginConfig
 	rootImportPath string
+	logger         *slog.Logger
 }
 
 func (m *ResolverPlugin) GenerateCode(data *codegen.Data) error {
@@ -92,6 +124,13 @@ func (m *ResolverPlugin) generateSingleFile(data *codegen.Data, models []*struct
 		addedAliases[scope.ImportAlias] = true
 	}
 
+	if hook := m.pluginConfig.ErrorPresenter; hook != nil && !addedAliases[hook.ImportAlias] {
+		file.Imports = append(file.Imports, Import{
+			Alias:      hook.ImportAlias,
+			ImportPath: hook.ImportPath,
+		})
+	}
+
 	for _, o := range data.Objects {
 		if o.HasResolvers() {
 			file.Objects = append(file.Objects, o)
@@ -105,12 +144,12 @@ func (m *ResolverPlugin) generateSingleFile(data *codegen.Data, models []*struct
 				Field:          f,
 				Implementation: `panic("not implemented yet")`,
 			}
-			enhanceResolver(m.pluginConfig, resolver, models)
+			enhanceResolver(m.pluginConfig, resolver, models, m.ModelCache.Naming, m.logger)
 			if resolver.Model.BoilerModel != nil && resolver.Model.BoilerModel.Name != "" {
 				file.Resolvers = append(file.Resolvers, resolver)
 			} else if resolver.Field.GoFieldName != "Node" {
-				// log.Debug().Str("resolver", resolver.Object.Name).Str("field", resolver.Field.GoFieldName).Msg(
-				//	"skipping resolver since no model found")
+				// m.logger.Debug("skipping resolver since no model found",
+				//	"resolver", resolver.Object.Name, "field", resolver.Field.GoFieldName)
 			}
 		}
 	}
@@ -120,15 +159,28 @@ func (m *ResolverPlugin) generateSingleFile(data *codegen.Data, models []*struct
 	resolverBasename := filepath.Base(m.resolverConfig.Filename)
 
 	// Scan for user-defined functions in the resolver directory, ignoring the generated file
-	userDefinedFunctions, err := customization.GetFunctionNamesFromDir(resolverDir, []string{resolverBasename})
+	userDefinedFunctions, err := customization.GetFunctionsFromDir(
+		resolverDir, m.resolverConfig.Package, []string{resolverBasename})
 	if err != nil {
-		log.Err(err).Msg("could not parse user defined resolver functions")
+		m.logger.Error("could not parse user defined resolver functions", "error", err)
 	}
 
-	// Convert to map for faster lookup in template
+	// Convert to map for faster lookup in template, resolvers are keyed on receiver and name e.g. queryResolver.User
 	userDefinedResolvers := make(map[string]bool)
 	for _, fn := range userDefinedFunctions {
-		userDefinedResolvers[fn] = true
+		userDefinedResolvers[fn.Key()] = true
+	}
+
+	// The generated resolvers log and instrument through the logger and instrumentation fields of the resolver when
+	// it has them
+	resolverFields, err := customization.GetStructFieldNamesFromDir(
+		resolverDir, m.resolverConfig.Package, []string{resolverBasename}, m.resolverConfig.Type)
+	if err != nil {
+		m.logger.Error("could not parse resolver struct", "error", err)
+	}
+	if m.pluginConfig.Instrumentation && !cache.SliceContains(resolverFields, "instrumentation") {
+		m.logger.Warn("instrumentation is enabled but the resolver has no instrumentation field, nothing is instrumented",
+			"resolver", m.resolverConfig.Type)
 	}
 
 	resolverBuild := &ResolverBuild{
@@ -136,23 +188,113 @@ func (m *ResolverPlugin) generateSingleFile(data *codegen.Data, models []*struct
 		PackageName:          m.resolverConfig.Package,
 		ResolverType:         m.resolverConfig.Type,
 		HasRoot:              false,
+		HasLogger:            cache.SliceContains(resolverFields, "logger"),
+		HasInstrumentation:   cache.SliceContains(resolverFields, "instrumentation"),
 		Models:               models,
 		AuthorizationScopes:  m.pluginConfig.AuthorizationScopes,
+		ErrorPresenter:       m.pluginConfig.ErrorPresenter,
+		Instrumentation:      m.pluginConfig.Instrumentation,
 		UserDefinedResolvers: userDefinedResolvers,
 	}
 
 	templateName := "generated_resolver.gotpl"
-	templateContent, err := getTemplateContent(templateName)
+	source, err := loadTemplate(m.pluginConfig.TemplateDirectory, templateName)
 	if err != nil {
-		log.Err(err).Msg("error when reading " + templateName)
+		m.logger.Error("error when reading "+templateName, "error", err)
 		return err
 	}
 
-	return templates.WriteTemplateFile(m.resolverConfig.Filename, templates.Options{
-		Template:    templateContent,
+	resolverOptions := templates.Options{
+		Name:        templateName,
+		Template:    source.Template,
+		Blocks:      source.Blocks,
 		PackageName: m.resolverConfig.Package,
 		Data:        resolverBuild,
-	})
+		Naming:      m.ModelCache.Naming,
+	}
+	// render every resolver first so the user defined resolvers can be checked against the generated ones like the
+	// overrides of the convert plugin
+	resolverBuild.UserDefinedResolvers = nil
+	generated, err := templates.RenderTemplateFile(m.resolverConfig.Filename, resolverOptions)
+	if err != nil {
+		return err
+	}
+	if err := customization.CheckSignatures(m.resolverConfig.Filename, generated, userDefinedFunctions); err != nil {
+		return fmt.Errorf("could not override %v: %w", m.resolverConfig.Filename, err)
+	}
+
+	stubs, err := m.overrideStubs(generated, userDefinedResolvers)
+	if err != nil {
+		return err
+	}
+	for _, stub := range stubs {
+		userDefinedResolvers[stub.Function.Key()] = true
+	}
+	// render again without the resolvers which are defined by the user or moved to the stubs
+	resolverBuild.UserDefinedResolvers = userDefinedResolvers
+	content, err := templates.RenderTemplateFile(m.resolverConfig.Filename, resolverOptions)
+	if err != nil {
+		return err
+	}
+
+	// render the complexity before writing so the resolvers are not updated when it can not be rendered
+	var complexityFileName string
+	var complexityContent []byte
+	if m.pluginConfig.Complexity != nil {
+		complexityFileName, complexityContent, err = m.renderComplexityFile(data, resolverBuild)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, stub := range stubs {
+		if err := m.writeFile(filepath.Join(resolverDir, stub.FileName), stub.Content); err != nil {
+			return err
+		}
+	}
+	if err := m.writeFile(m.resolverConfig.Filename, content); err != nil {
+		return err
+	}
+	if complexityContent != nil {
+		return m.writeFile(complexityFileName, complexityContent)
+	}
+	return nil
+}
+
+// overrideStubs copies the generated resolvers of the OverrideStubs which are not defined by the user yet
+func (m *ResolverPlugin) overrideStubs(content []byte, userDefinedResolvers map[string]bool) ([]customization.Stub, error) {
+	var pending []string
+	for _, key := range m.pluginConfig.OverrideStubs {
+		if !userDefinedResolvers[key] {
+			pending = append(pending, key)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	stubs, err := customization.OverrideStubs(m.resolverConfig.Filename, content, "resolver", pending, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, stub := range stubs {
+		// the stub is owned by the user so an existing file is never replaced
+		fileName := filepath.Join(filepath.Dir(m.resolverConfig.Filename), stub.FileName)
+		if _, err := os.Stat(fileName); err == nil {
+			return nil, fmt.Errorf("%v already exists, remove it to create the override stub again", fileName)
+		}
+		pending = removeString(pending, stub.Function.Key())
+	}
+	if len(pending) > 0 {
+		return nil, fmt.Errorf("could not create override stubs, %v are not generated", strings.Join(pending, ", "))
+	}
+	return stubs, nil
+}
+
+func (m *ResolverPlugin) writeFile(fileName string, content []byte) error {
+	if m.pluginConfig.DryRun != nil {
+		return m.pluginConfig.DryRun.Compare(fileName, content)
+	}
+	return templates.WriteFile(fileName, content)
 }
 
 func buildImportPath(rootImportPath, directory string) string {
@@ -166,20 +308,31 @@ func buildImportPath(rootImportPath, directory string) string {
 type ResolverBuild struct {
 	*File
 	HasRoot              bool
+	HasLogger            bool
+	HasInstrumentation   bool
 	PackageName          string
 	ResolverType         string
 	Models               []*structs.Model
 	AuthorizationScopes  []*AuthorizationScope
+	ErrorPresenter       *ErrorPresenterHook
+	Instrumentation      bool
 	TryHook              func(string) bool
 	UserDefinedResolvers map[string]bool
 }
 
-// IsResolverOverridden checks if the resolver function is overridden by user
-func (rb *ResolverBuild) IsResolverOverridden(name string) bool {
+// IsResolverOverridden checks if the resolver method is defined by the user on the same receiver
+func (rb *ResolverBuild) IsResolverOverridden(resolver *Resolver) bool {
 	if rb.UserDefinedResolvers == nil {
 		return false
 	}
-	return rb.UserDefinedResolvers[name]
+	receiver := gqlgenTemplates.LcFirst(resolver.Object.Name) + gqlgenTemplates.UcFirst(rb.ResolverType)
+	return rb.UserDefinedResolvers[customization.FunctionKey(receiver, resolver.Field.GoFieldName)]
+}
+
+// IsQueryResolverOverridden checks if a query resolver without model e.g. Node is defined by the user
+func (rb *ResolverBuild) IsQueryResolverOverridden(name string) bool {
+	receiver := "query" + gqlgenTemplates.UcFirst(rb.ResolverType)
+	return rb.UserDefinedResolvers[customization.FunctionKey(receiver, name)]
 }
 
 type File struct {
@@ -200,6 +353,7 @@ type Resolver struct {
 	IsList                    bool
 	IsListForward             bool
 	IsListBackward            bool
+	IsPageSizeOptional        bool
 	IsCreate                  bool
 	IsUpdate                  bool
 	IsDelete                  bool
@@ -240,21 +394,58 @@ func (rb *ResolverBuild) ShortResolverDeclaration(r *Resolver) string {
 		res += fmt.Sprintf(", %s %s", arg.VarName, rb.getResolverType(arg.TypeReference.GO.String()))
 	}
 
+	res += fmt.Sprintf(") (%s, error)", rb.ResolverResultType(r))
+	return res
+}
+
+// ResolverResultType returns the type of the first result of the resolver
+func (rb *ResolverBuild) ResolverResultType(r *Resolver) string {
 	result := rb.getResolverType(r.Field.TypeReference.GO.String())
 	if r.Field.Object.Stream {
 		result = "<-chan " + result
 	}
+	return result
+}
 
-	res += fmt.Sprintf(") (%s, error)", result)
-	return res
+// HasArg returns true if the resolver has an argument with the given name e.g. id or filter
+func (r *Resolver) HasArg(name string) bool {
+	for _, arg := range r.Field.Args {
+		if arg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// OperationName is the kind of operation of the resolver, used as name of the instrumented operation
+func (r *Resolver) OperationName() string {
+	switch {
+	case r.IsSingle:
+		return "single"
+	case r.IsList:
+		return "list"
+	case r.IsCreate:
+		return "create"
+	case r.IsUpdate:
+		return "update"
+	case r.IsDelete:
+		return "delete"
+	case r.IsBatchCreate:
+		return "batchCreate"
+	case r.IsBatchUpdate:
+		return "batchUpdate"
+	case r.IsBatchDelete:
+		return "batchDelete"
+	}
+	return r.Field.GoFieldName
 }
 
-func enhanceResolver(resolverConfig ResolverPluginConfig, r *Resolver, models []*structs.Model) { //nolint:gocyclo
+func enhanceResolver(resolverConfig ResolverPluginConfig, r *Resolver, models []*structs.Model, strategy naming.Strategy,
+	logger *slog.Logger) { //nolint:gocyclo
 	nameOfResolver := r.Field.GoFieldName
 
 	// get model names + model convert information
-	modelName, inputModelName := getModelNames(nameOfResolver, false)
-	// modelPluralName, _ := getModelNames(nameOfResolver, true)
+	modelName, inputModelName, isPlural := getModelNames(models, nameOfResolver, strategy)
 
 	model := findModelOrEmpty(models, modelName)
 	inputModel := findModelOrEmpty(models, inputModelName)
@@ -265,31 +456,30 @@ func enhanceResolver(resolverConfig ResolverPluginConfig, r *Resolver, models []
 
 	switch r.Object.Name {
 	case "Mutation":
-		r.IsCreate = containsPrefixAndPartAfterThatIsSingle(nameOfResolver, "Create")
-		r.IsUpdate = containsPrefixAndPartAfterThatIsSingle(nameOfResolver, "Update")
-		r.IsDelete = containsPrefixAndPartAfterThatIsSingle(nameOfResolver, "Delete")
-		r.IsBatchCreate = containsPrefixAndPartAfterThatIsPlural(nameOfResolver, "Create")
-		r.IsBatchUpdate = containsPrefixAndPartAfterThatIsPlural(nameOfResolver, "Update")
-		r.IsBatchDelete = containsPrefixAndPartAfterThatIsPlural(nameOfResolver, "Delete")
-		if resolverConfig.EnableSoftDeletes == true && model.HasDeletedAt {
-			r.SoftDeleteSuffix = ", false"
+		r.IsCreate = strings.HasPrefix(nameOfResolver, "Create") && !isPlural
+		r.IsUpdate = strings.HasPrefix(nameOfResolver, "Update") && !isPlural
+		r.IsDelete = strings.HasPrefix(nameOfResolver, "Delete") && !isPlural
+		r.IsBatchCreate = strings.HasPrefix(nameOfResolver, "Create") && isPlural
+		r.IsBatchUpdate = strings.HasPrefix(nameOfResolver, "Update") && isPlural
+		r.IsBatchDelete = strings.HasPrefix(nameOfResolver, "Delete") && isPlural
+		// sqlboiler models with a deleted_at column need to know if the delete is a hard delete like in the crud helpers
+		if model.HasDeletedAt {
+			r.SoftDeleteSuffix = ", " + strconv.FormatBool(!resolverConfig.EnableSoftDeletes)
 		}
 	case "Query":
-		isPlural := cache.IsPlural(nameOfResolver)
 		if isPlural {
 			r.IsList = isPlural
-			r.IsListBackward = strings.Contains(r.Field.GoFieldName, "first int") &&
-				strings.Contains(r.Field.GoFieldName, "after *string")
-			r.IsListBackward = strings.Contains(r.Field.GoFieldName, "last int") &&
-				strings.Contains(r.Field.GoFieldName, "before *string")
+			r.IsListForward = r.HasArg("first") && r.HasArg("after")
+			r.IsListBackward = r.HasArg("last") && r.HasArg("before")
 		}
 
 		r.IsSingle = !r.IsList
+		r.IsPageSizeOptional = isPageSizeOptional(r.Field)
 	case "Subscription":
 	// TODO: generate helpers for subscription
 	default:
-		log.Warn().Str("unknown", r.Object.Name).Msg(
-			"only Query and Mutation are handled we don't recognize the following")
+		logger.Warn("only Query and Mutation are handled we don't recognize the following",
+			"unknown", r.Object.Name)
 	}
 
 	lmName := strcase.ToLowerCamel(model.Name)
@@ -331,6 +521,16 @@ func enhanceResolver(resolverConfig ResolverPluginConfig, r *Resolver, models []
 	r.PublicErrorKey += "Error"
 }
 
+// isPageSizeOptional returns true if first or last is nullable, e.g. when the schema has a default page size
+func isPageSizeOptional(field *codegen.Field) bool {
+	for _, arg := range field.Args {
+		if (arg.Name == "first" || arg.Name == "last") && arg.TypeReference.IsPtr() {
+			return true
+		}
+	}
+	return false
+}
+
 func findModelOrEmpty(models []*structs.Model, modelName string) structs.Model {
 	if modelName == "" {
 		return structs.Model{}
@@ -345,36 +545,38 @@ func findModelOrEmpty(models []*structs.Model, modelName string) structs.Model {
 
 var InputTypes = []string{"Create", "Update", "Delete"} //nolint:gochecknoglobals
 
-func getModelNames(v string, plural bool) (modelName, inputModelName string) {
+// getModelNames returns the model of a resolver and if the resolver is for a list of the model e.g. User and true for
+// users or createUsers. The name is matched with the names and plural names of the models before it is inflected, so
+// models like News with plural NewsList or with plurals of the naming strategy are found.
+func getModelNames(models []*structs.Model, v string, strategy naming.Strategy) (modelName, inputModelName string, isPlural bool) {
 	var prefix string
-	var isInputType bool
 	for _, inputType := range InputTypes {
 		if strings.HasPrefix(v, inputType) {
-			isInputType = true
 			v = strings.TrimPrefix(v, inputType)
 			prefix = inputType
 		}
 	}
-	var s string
-	if plural {
-		s = cache.Plural(v)
+
+	if model := findModelByNameOrPluralName(models, v); model != nil {
+		modelName = model.Name
+		isPlural = !strings.EqualFold(model.Name, v)
 	} else {
-		s = cache.Singular(v)
+		modelName = strategy.Singular(v)
+		isPlural = strategy.IsPlural(v)
 	}
 
-	if isInputType {
-		return s, s + prefix + "Input"
+	if prefix != "" {
+		return modelName, modelName + prefix + "Input", isPlural
 	}
 
-	return s, ""
+	return modelName, "", isPlural
 }
 
-func containsPrefixAndPartAfterThatIsSingle(v string, prefix string) bool {
-	partAfterThat := strings.TrimPrefix(v, prefix)
-	return strings.HasPrefix(v, prefix) && cache.IsSingular(partAfterThat)
-}
-
-func containsPrefixAndPartAfterThatIsPlural(v string, prefix string) bool {
-	partAfterThat := strings.TrimPrefix(v, prefix)
-	return strings.HasPrefix(v, prefix) && cache.IsPlural(partAfterThat)
+func findModelByNameOrPluralName(models []*structs.Model, name string) *structs.Model {
+	for _, m := range models {
+		if m.IsNormal && m.HasBoilerModel && (strings.EqualFold(m.Name, name) || strings.EqualFold(m.PluralName, name)) {
+			return m
+		}
+	}
+	return nil
 }
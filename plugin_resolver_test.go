@@ -0,0 +1,50 @@
+package gbgen
+
+import (
+	"testing"
+
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/naming"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
+)
+
+func TestGetModelNames(t *testing.T) {
+	strategy := naming.Strategy{Plurals: map[string]string{"huis": "huizen"}, ListSuffix: "List"}
+
+	var models []*structs.Model
+	for _, name := range []string{"User", "News", "Datum", "OrderStatus", "Huis"} {
+		models = append(models, &structs.Model{
+			Name:           name,
+			PluralName:     strategy.Plural(name),
+			IsNormal:       true,
+			HasBoilerModel: true,
+		})
+	}
+
+	tests := []struct {
+		resolver       string
+		modelName      string
+		inputModelName string
+		isPlural       bool
+	}{
+		{resolver: "User", modelName: "User"},
+		{resolver: "Users", modelName: "User", isPlural: true},
+		{resolver: "CreateUser", modelName: "User", inputModelName: "UserCreateInput"},
+		{resolver: "CreateUsers", modelName: "User", inputModelName: "UserCreateInput", isPlural: true},
+		{resolver: "News", modelName: "News"},
+		{resolver: "NewsList", modelName: "News", isPlural: true},
+		{resolver: "DeleteNewsList", modelName: "News", inputModelName: "NewsDeleteInput", isPlural: true},
+		{resolver: "Data", modelName: "Datum", isPlural: true},
+		{resolver: "UpdateOrderStatuses", modelName: "OrderStatus", inputModelName: "OrderStatusUpdateInput", isPlural: true},
+		{resolver: "Huizen", modelName: "Huis", isPlural: true},
+		{resolver: "Accounts", modelName: "Account", isPlural: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resolver, func(t *testing.T) {
+			modelName, inputModelName, isPlural := getModelNames(models, tt.resolver, strategy)
+			if modelName != tt.modelName || inputModelName != tt.inputModelName || isPlural != tt.isPlural {
+				t.Errorf("getModelNames() = %v, %v, %v, want %v, %v, %v", modelName, inputModelName, isPlural,
+					tt.modelName, tt.inputModelName, tt.isPlural)
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package gbgen
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/cache"
+)
+
+type SchemaChangeKind string
+
+const (
+	SchemaChangeTypeRemoved          SchemaChangeKind = "TypeRemoved"
+	SchemaChangeTypeAdded            SchemaChangeKind = "TypeAdded"
+	SchemaChangeTypeKindChanged      SchemaChangeKind = "TypeKindChanged"
+	SchemaChangeFieldRemoved         SchemaChangeKind = "FieldRemoved"
+	SchemaChangeFieldAdded           SchemaChangeKind = "FieldAdded"
+	SchemaChangeFieldDeprecated      SchemaChangeKind = "FieldDeprecated"
+	SchemaChangeFieldTypeChanged     SchemaChangeKind = "FieldTypeChanged"
+	SchemaChangeNullabilityTightened SchemaChangeKind = "NullabilityTightened"
+	SchemaChangeNullabilityLoosened  SchemaChangeKind = "NullabilityLoosened"
+	SchemaChangeArgumentRemoved      SchemaChangeKind = "ArgumentRemoved"
+	SchemaChangeArgumentAdded        SchemaChangeKind = "ArgumentAdded"
+	SchemaChangeDefaultValueChanged  SchemaChangeKind = "DefaultValueChanged"
+	SchemaChangeEnumValueRemoved     SchemaChangeKind = "EnumValueRemoved"
+	SchemaChangeEnumValueAdded       SchemaChangeKind = "EnumValueAdded"
+	SchemaChangeUnionMemberRemoved   SchemaChangeKind = "UnionMemberRemoved"
+	SchemaChangeUnionMemberAdded     SchemaChangeKind = "UnionMemberAdded"
+	SchemaChangeInterfaceRemoved     SchemaChangeKind = "InterfaceRemoved"
+	SchemaChangeInterfaceAdded       SchemaChangeKind = "InterfaceAdded"
+	SchemaChangeDirectiveRemoved     SchemaChangeKind = "DirectiveRemoved"
+	SchemaChangeDirectiveAdded       SchemaChangeKind = "DirectiveAdded"
+)
+
+// SchemaChangeSeverity tells whether a change breaks clients, could change the behavior of clients e.g. a new enum
+// value which a client does not handle, or is safe
+type SchemaChangeSeverity string
+
+const (
+	SchemaChangeBreaking  SchemaChangeSeverity = "BREAKING"
+	SchemaChangeDangerous SchemaChangeSeverity = "DANGEROUS"
+	SchemaChangeSafe      SchemaChangeSeverity = "SAFE"
+)
+
+type SchemaChange struct {
+	Kind     SchemaChangeKind
+	Severity SchemaChangeSeverity
+	// Type is the name of the changed type or directive e.g. User
+	Type string
+	// Field is the name of the changed field, argument e.g. users(first:) or enum value, empty when a type is changed
+	Field   string
+	Message string
+}
+
+type SchemaChangeReport struct {
+	Changes []SchemaChange
+}
+
+// Breaking returns the changes which break clients
+func (r SchemaChangeReport) Breaking() []SchemaChange {
+	return r.bySeverity(SchemaChangeBreaking)
+}
+
+func (r SchemaChangeReport) bySeverity(severity SchemaChangeSeverity) []SchemaChange {
+	var a []SchemaChange
+	for _, change := range r.Changes {
+		if change.Severity == severity {
+			a = append(a, change)
+		}
+	}
+	return a
+}
+
+// String returns the changes grouped by severity
+func (r SchemaChangeReport) String() string {
+	if len(r.Changes) == 0 {
+		return "No changes"
+	}
+	var lines []string
+	for _, severity := range []SchemaChangeSeverity{SchemaChangeBreaking, SchemaChangeDangerous, SchemaChangeSafe} {
+		changes := r.bySeverity(severity)
+		if len(changes) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%v (%d)", severity, len(changes)))
+		for _, change := range changes {
+			name := change.Type
+			if change.Field != "" {
+				name += "." + change.Field
+			}
+			lines = append(lines, fmt.Sprintf("  %v %v: %v", change.Kind, name, change.Message))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+type SchemaCheckConfig struct {
+	// Evolution keeps the fields of removed or renamed columns like SchemaWrite does
+	Evolution *SchemaEvolution
+	// AllowBreakingChanges makes SchemaCheck succeed when the schema contains breaking changes
+	AllowBreakingChanges bool
+	// Output receives the report, defaults to os.Stdout
+	Output io.Writer
+}
+
+// SchemaCheck compares the committed schema with the schema which would be generated now and prints the changes. It
+// returns an error when there are breaking changes which are not allowed, so CI can exit with a non-zero code. The
+// committed schema is not changed.
+func SchemaCheck(config SchemaConfig, schemaFile string, checkOptions SchemaCheckConfig) (SchemaChangeReport, error) {
+	var report SchemaChangeReport
+	if !fileExists(schemaFile) {
+		return report, fmt.Errorf("schema %v does not exist", schemaFile)
+	}
+	if checkOptions.Evolution != nil {
+		evolution := *checkOptions.Evolution
+		// the committed schema is the previous schema, the report is checked below
+		evolution.PreviousSchemaFile = schemaFile
+		var err error
+		if report, err = evolveSchema(&config, schemaFile, evolution); err != nil {
+			return report, err
+		}
+	} else {
+		previous, err := parseSchemaFile(schemaFile)
+		if err != nil {
+			return report, err
+		}
+		current, err := parser.ParseSchema(&ast.Source{Name: schemaFile, Input: SchemaGet(config)})
+		if err != nil {
+			return report, fmt.Errorf("could not parse generated schema: %w", err)
+		}
+		report = compareSchemas(previous, current)
+	}
+
+	output := checkOptions.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	if _, err := fmt.Fprintln(output, report); err != nil {
+		return report, err
+	}
+	if breaking := report.Breaking(); len(breaking) > 0 && !checkOptions.AllowBreakingChanges {
+		return report, fmt.Errorf("schema contains %d breaking changes", len(breaking))
+	}
+	return report, nil
+}
+
+func parseSchemaFile(schemaFile string) (*ast.SchemaDocument, error) {
+	content, err := os.ReadFile(schemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read schema: %w", err)
+	}
+	document, err := parser.ParseSchema(&ast.Source{Name: schemaFile, Input: string(content)})
+	if err != nil {
+		return nil, fmt.Errorf("could not parse schema: %w", err)
+	}
+	return document, nil
+}
+
+// compareSchemas classifies the differences between two schemas like graphql-inspector does
+func compareSchemas(previous, current *ast.SchemaDocument) SchemaChangeReport { //nolint:gocognit,gocyclo
+	previous, current = mergeExtensions(previous), mergeExtensions(current)
+
+	var report SchemaChangeReport
+	add := func(kind SchemaChangeKind, severity SchemaChangeSeverity, typeName, field, message string, args ...interface{}) {
+		report.Changes = append(report.Changes, SchemaChange{
+			Kind:     kind,
+			Severity: severity,
+			Type:     typeName,
+			Field:    field,
+			Message:  fmt.Sprintf(message, args...),
+		})
+	}
+
+	for _, definition := range previous.Definitions {
+		currentDefinition := current.Definitions.ForName(definition.Name)
+		if currentDefinition == nil {
+			add(SchemaChangeTypeRemoved, SchemaChangeBreaking, definition.Name, "", "type is removed")
+			continue
+		}
+		if definition.Kind != currentDefinition.Kind {
+			add(SchemaChangeTypeKindChanged, SchemaChangeBreaking, definition.Name, "",
+				"kind changed from %v to %v", definition.Kind, currentDefinition.Kind)
+			continue
+		}
+
+		for _, value := range definition.EnumValues {
+			if currentDefinition.EnumValues.ForName(value.Name) == nil {
+				add(SchemaChangeEnumValueRemoved, SchemaChangeBreaking, definition.Name, value.Name, "enum value is removed")
+			}
+		}
+		for _, value := range currentDefinition.EnumValues {
+			if definition.EnumValues.ForName(value.Name) == nil {
+				add(SchemaChangeEnumValueAdded, SchemaChangeDangerous, definition.Name, value.Name,
+					"enum value is added, clients could receive a value they do not know")
+			}
+		}
+		for _, member := range missing(definition.Types, currentDefinition.Types) {
+			add(SchemaChangeUnionMemberRemoved, SchemaChangeBreaking, definition.Name, member, "union member is removed")
+		}
+		for _, member := range missing(currentDefinition.Types, definition.Types) {
+			add(SchemaChangeUnionMemberAdded, SchemaChangeDangerous, definition.Name, member,
+				"union member is added, clients could receive a type they do not know")
+		}
+		for _, name := range missing(definition.Interfaces, currentDefinition.Interfaces) {
+			add(SchemaChangeInterfaceRemoved, SchemaChangeBreaking, definition.Name, name, "interface is removed")
+		}
+		for _, name := range missing(currentDefinition.Interfaces, definition.Interfaces) {
+			add(SchemaChangeInterfaceAdded, SchemaChangeSafe, definition.Name, name, "interface is added")
+		}
+
+		isInput := definition.Kind == ast.InputObject
+		for _, field := range definition.Fields {
+			currentField := currentDefinition.Fields.ForName(field.Name)
+			if currentField == nil {
+				add(SchemaChangeFieldRemoved, SchemaChangeBreaking, definition.Name, field.Name, "field is removed")
+				continue
+			}
+			if kind, severity, ok := compareTypes(field.Type, currentField.Type, isInput); ok {
+				// clients are warned by the deprecation when a deprecated field starts to return null
+				if kind == SchemaChangeNullabilityLoosened && !isInput && isDeprecated(currentField.Directives) {
+					severity = SchemaChangeDangerous
+				}
+				add(kind, severity, definition.Name, field.Name,
+					"type changed from %v to %v", field.Type.String(), currentField.Type.String())
+			}
+			if isInput && defaultValue(field.DefaultValue) != defaultValue(currentField.DefaultValue) {
+				add(SchemaChangeDefaultValueChanged, SchemaChangeDangerous, definition.Name, field.Name,
+					"default value changed from %v to %v",
+					defaultValue(field.DefaultValue), defaultValue(currentField.DefaultValue))
+			}
+			if !isDeprecated(field.Directives) && isDeprecated(currentField.Directives) {
+				add(SchemaChangeFieldDeprecated, SchemaChangeSafe, definition.Name, field.Name, "field is deprecated")
+			}
+			compareArguments(add, definition.Name, field, currentField)
+		}
+		for _, field := range currentDefinition.Fields {
+			if definition.Fields.ForName(field.Name) != nil {
+				continue
+			}
+			switch {
+			case isInput && field.Type.NonNull && field.DefaultValue == nil:
+				add(SchemaChangeFieldAdded, SchemaChangeBreaking, definition.Name, field.Name,
+					"required input field is added")
+			case isInput:
+				add(SchemaChangeFieldAdded, SchemaChangeDangerous, definition.Name, field.Name, "input field is added")
+			default:
+				add(SchemaChangeFieldAdded, SchemaChangeSafe, definition.Name, field.Name, "field is added")
+			}
+		}
+	}
+	for _, definition := range current.Definitions {
+		if previous.Definitions.ForName(definition.Name) == nil {
+			add(SchemaChangeTypeAdded, SchemaChangeSafe, definition.Name, "", "type is added")
+		}
+	}
+
+	for _, directive := range previous.Directives {
+		if current.Directives.ForName(directive.Name) == nil {
+			add(SchemaChangeDirectiveRemoved, SchemaChangeBreaking, "@"+directive.Name, "", "directive is removed")
+		}
+	}
+	for _, directive := range current.Directives {
+		if previous.Directives.ForName(directive.Name) == nil {
+			add(SchemaChangeDirectiveAdded, SchemaChangeSafe, "@"+directive.Name, "", "directive is added")
+		}
+	}
+	return report
+}
+
+func compareArguments(
+	add func(kind SchemaChangeKind, severity SchemaChangeSeverity, typeName, field, message string, args ...interface{}),
+	typeName string,
+	field, currentField *ast.FieldDefinition,
+) {
+	for _, argument := range field.Arguments {
+		name := field.Name + "(" + argument.Name + ":)"
+		currentArgument := currentField.Arguments.ForName(argument.Name)
+		if currentArgument == nil {
+			add(SchemaChangeArgumentRemoved, SchemaChangeBreaking, typeName, name, "argument is removed")
+			continue
+		}
+		if kind, severity, ok := compareTypes(argument.Type, currentArgument.Type, true); ok {
+			add(kind, severity, typeName, name,
+				"type changed from %v to %v", argument.Type.String(), currentArgument.Type.String())
+		}
+		if defaultValue(argument.DefaultValue) != defaultValue(currentArgument.DefaultValue) {
+			add(SchemaChangeDefaultValueChanged, SchemaChangeDangerous, typeName, name,
+				"default value changed from %v to %v",
+				defaultValue(argument.DefaultValue), defaultValue(currentArgument.DefaultValue))
+		}
+	}
+	for _, argument := range currentField.Arguments {
+		if field.Arguments.ForName(argument.Name) != nil {
+			continue
+		}
+		name := field.Name + "(" + argument.Name + ":)"
+		if argument.Type.NonNull && argument.DefaultValue == nil {
+			add(SchemaChangeArgumentAdded, SchemaChangeBreaking, typeName, name, "required argument is added")
+		} else {
+			add(SchemaChangeArgumentAdded, SchemaChangeDangerous, typeName, name, "argument is added")
+		}
+	}
+}
+
+// mergeExtensions returns a copy of the document in which the fields, enum values, union members, interfaces and
+// directives of an extension (extend type User { ... }) are added to the definition it extends. An extension of a
+// type which is defined in another file is used as the definition.
+func mergeExtensions(document *ast.SchemaDocument) *ast.SchemaDocument {
+	if len(document.Extensions) == 0 {
+		return document
+	}
+	merged := *document
+	merged.Extensions = nil
+	merged.Definitions = make(ast.DefinitionList, 0, len(document.Definitions))
+	for _, definition := range document.Definitions {
+		d := *definition
+		merged.Definitions = append(merged.Definitions, &d)
+	}
+	for _, extension := range document.Extensions {
+		definition := merged.Definitions.ForName(extension.Name)
+		if definition == nil {
+			d := *extension
+			merged.Definitions = append(merged.Definitions, &d)
+			continue
+		}
+		// the slices are copied so the parsed document is not changed
+		definition.Fields = append(append(ast.FieldList{}, definition.Fields...), extension.Fields...)
+		definition.EnumValues = append(append(ast.EnumValueList{}, definition.EnumValues...), extension.EnumValues...)
+		definition.Types = append(append([]string{}, definition.Types...), extension.Types...)
+		definition.Interfaces = append(append([]string{}, definition.Interfaces...), extension.Interfaces...)
+		definition.Directives = append(append(ast.DirectiveList{}, definition.Directives...), extension.Directives...)
+	}
+	return &merged
+}
+
+// compareTypes classifies a changed type of a field or argument. A field which becomes nullable breaks clients which
+// read it, an input field or argument which becomes required breaks clients which do not send it.
+func compareTypes(previous, current *ast.Type, isInput bool) (SchemaChangeKind, SchemaChangeSeverity, bool) {
+	if previous.String() == current.String() {
+		return "", "", false
+	}
+	tightened, loosened, sameShape := compareNullability(previous, current)
+	switch {
+	case !sameShape:
+		return SchemaChangeFieldTypeChanged, SchemaChangeBreaking, true
+	case isInput && tightened:
+		return SchemaChangeNullabilityTightened, SchemaChangeBreaking, true
+	case isInput:
+		return SchemaChangeNullabilityLoosened, SchemaChangeSafe, true
+	case loosened:
+		return SchemaChangeNullabilityLoosened, SchemaChangeBreaking, true
+	default:
+		return SchemaChangeNullabilityTightened, SchemaChangeSafe, true
+	}
+}
+
+// compareNullability returns whether a type became non-null or nullable on any level of its lists and whether the
+// types are the same without nullability
+func compareNullability(previous, current *ast.Type) (tightened bool, loosened bool, sameShape bool) {
+	if previous.NamedType != current.NamedType || (previous.Elem == nil) != (current.Elem == nil) {
+		return false, false, false
+	}
+	tightened = !previous.NonNull && current.NonNull
+	loosened = previous.NonNull && !current.NonNull
+	if previous.Elem == nil {
+		return tightened, loosened, true
+	}
+	elemTightened, elemLoosened, sameShape := compareNullability(previous.Elem, current.Elem)
+	return tightened || elemTightened, loosened || elemLoosened, sameShape
+}
+
+func isDeprecated(directives ast.DirectiveList) bool {
+	return directives.ForName("deprecated") != nil
+}
+
+func defaultValue(v *ast.Value) string {
+	if v == nil {
+		return "none"
+	}
+	return v.String()
+}
+
+// missing returns the values of a which are not in b
+func missing(a, b []string) []string {
+	var values []string
+	for _, v := range a {
+		if !cache.SliceContains(b, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,115 @@
+package gbgen
+
+import (
+	"testing"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestCompareSchemas(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		current  string
+		want     []SchemaChange
+	}{
+		{
+			name:     "removed type",
+			previous: `type User { id: ID! } type Post { id: ID! }`,
+			current:  `type User { id: ID! }`,
+			want:     []SchemaChange{{Kind: SchemaChangeTypeRemoved, Severity: SchemaChangeBreaking, Type: "Post"}},
+		},
+		{
+			name:     "output field becomes nullable",
+			previous: `type User { name: String! tags: [String!]! }`,
+			current:  `type User { name: String tags: [String]! }`,
+			want: []SchemaChange{
+				{Kind: SchemaChangeNullabilityLoosened, Severity: SchemaChangeBreaking, Type: "User", Field: "name"},
+				{Kind: SchemaChangeNullabilityLoosened, Severity: SchemaChangeBreaking, Type: "User", Field: "tags"},
+			},
+		},
+		{
+			name:     "deprecated field becomes nullable",
+			previous: `type User { name: String! }`,
+			current:  `type User { name: String @deprecated(reason: "Removed from the database on 2026-10-19") }`,
+			want: []SchemaChange{
+				{Kind: SchemaChangeNullabilityLoosened, Severity: SchemaChangeDangerous, Type: "User", Field: "name"},
+				{Kind: SchemaChangeFieldDeprecated, Severity: SchemaChangeSafe, Type: "User", Field: "name"},
+			},
+		},
+		{
+			name:     "input fields",
+			previous: `input UserCreateInput { name: String email: String! age: Int = 1 }`,
+			current:  `input UserCreateInput { name: String! email: String age: Int = 2 role: String! bio: String }`,
+			want: []SchemaChange{
+				{Kind: SchemaChangeNullabilityTightened, Severity: SchemaChangeBreaking, Type: "UserCreateInput", Field: "name"},
+				{Kind: SchemaChangeNullabilityLoosened, Severity: SchemaChangeSafe, Type: "UserCreateInput", Field: "email"},
+				{Kind: SchemaChangeDefaultValueChanged, Severity: SchemaChangeDangerous, Type: "UserCreateInput", Field: "age"},
+				{Kind: SchemaChangeFieldAdded, Severity: SchemaChangeBreaking, Type: "UserCreateInput", Field: "role"},
+				{Kind: SchemaChangeFieldAdded, Severity: SchemaChangeDangerous, Type: "UserCreateInput", Field: "bio"},
+			},
+		},
+		{
+			name:     "renamed enum value",
+			previous: `enum UserRole { ADMIN SUPERADMIN }`,
+			current:  `enum UserRole { ADMIN SUPER_ADMIN }`,
+			want: []SchemaChange{
+				{Kind: SchemaChangeEnumValueRemoved, Severity: SchemaChangeBreaking, Type: "UserRole", Field: "SUPERADMIN"},
+				{Kind: SchemaChangeEnumValueAdded, Severity: SchemaChangeDangerous, Type: "UserRole", Field: "SUPER_ADMIN"},
+			},
+		},
+		{
+			name:     "arguments",
+			previous: `type Query { users(first: Int!, after: String): [ID!]! }`,
+			current:  `type Query { users(first: Int, filter: String!): [ID!]! }`,
+			want: []SchemaChange{
+				{Kind: SchemaChangeNullabilityLoosened, Severity: SchemaChangeSafe, Type: "Query", Field: "users(first:)"},
+				{Kind: SchemaChangeArgumentRemoved, Severity: SchemaChangeBreaking, Type: "Query", Field: "users(after:)"},
+				{Kind: SchemaChangeArgumentAdded, Severity: SchemaChangeBreaking, Type: "Query", Field: "users(filter:)"},
+			},
+		},
+		{
+			name:     "field removed from extension",
+			previous: `type User { id: ID! } extend type User { nickname: String } extend type Query { me: User }`,
+			current:  `type User { id: ID! } extend type User { age: Int } extend type Query { me: User }`,
+			want: []SchemaChange{
+				{Kind: SchemaChangeFieldRemoved, Severity: SchemaChangeBreaking, Type: "User", Field: "nickname"},
+				{Kind: SchemaChangeFieldAdded, Severity: SchemaChangeSafe, Type: "User", Field: "age"},
+			},
+		},
+		{
+			name:     "extension becomes part of the definition",
+			previous: `type User { id: ID! } extend type User { nickname: String }`,
+			current:  `type User { id: ID! nickname: String }`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := compareSchemas(mustParseSchema(t, tt.previous), mustParseSchema(t, tt.current))
+			var got []SchemaChange
+			for _, change := range report.Changes {
+				// the message is not compared
+				change.Message = ""
+				got = append(got, change)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("change %d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func mustParseSchema(t *testing.T, schema string) *ast.SchemaDocument {
+	t.Helper()
+	document, err := parser.ParseSchema(&ast.Source{Input: schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return document
+}
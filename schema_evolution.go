@@ -0,0 +1,162 @@
+package gbgen
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/cache"
+)
+
+const defaultGracePeriod = 30 * 24 * time.Hour
+
+// SchemaEvolution compares the new schema with the previously generated schema before it is written. Fields of removed
+// or renamed columns are kept with @deprecated during the grace period and breaking changes are reported.
+type SchemaEvolution struct {
+	// PreviousSchemaFile is the schema which was generated the last time, defaults to the output file. Set it when the
+	// output file is merged with changes which are written by hand, the generated schema is also written to this file.
+	PreviousSchemaFile string
+	// GracePeriod is how long the field of a removed or renamed column is kept, defaults to 30 days
+	GracePeriod time.Duration
+	// Renames are the new names of renamed fields by Type.field e.g. "User.lastName": "familyName", the old field
+	// returns the value of the renamed column
+	Renames map[string]string
+	// AllowBreakingChanges writes a schema with breaking changes, otherwise SchemaWrite returns an error
+	AllowBreakingChanges bool
+	// Report is called with all changes before the schema is written
+	Report func(report SchemaChangeReport)
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+// deprecatedField is a field of a removed or renamed column which is kept in the type of the model
+type deprecatedField struct {
+	Name   string
+	Type   string
+	Reason string
+}
+
+// evolveSchema compares the schema of the config with the previously generated schema and adds the deprecated fields
+// to the config
+func evolveSchema(config *SchemaConfig, outputFile string, evolution SchemaEvolution) (SchemaChangeReport, error) {
+	previousFile := evolution.PreviousSchemaFile
+	if previousFile == "" {
+		previousFile = outputFile
+	}
+	if !fileExists(previousFile) {
+		return SchemaChangeReport{}, nil
+	}
+	previous, err := parseSchemaFile(previousFile)
+	if err != nil {
+		return SchemaChangeReport{}, err
+	}
+
+	config.deprecatedFields = nil
+	current, err := parser.ParseSchema(&ast.Source{Name: outputFile, Input: SchemaGet(*config)})
+	if err != nil {
+		return SchemaChangeReport{}, fmt.Errorf("could not parse generated schema: %w", err)
+	}
+
+	modelNames := map[string]bool{}
+	for _, model := range executeHooksOnModels(boilerModelsToModels(config.BoilerCache.BoilerModels, config.BoilerCache.Naming), *config) {
+		modelNames[model.Name] = true
+	}
+	now := time.Now
+	if evolution.Now != nil {
+		now = evolution.Now
+	}
+	config.deprecatedFields = keepDeprecatedFields(previous, current, modelNames, evolution, now(), config.logger())
+
+	// compare with the schema which contains the deprecated fields
+	current, err = parser.ParseSchema(&ast.Source{Name: outputFile, Input: SchemaGet(*config)})
+	if err != nil {
+		return SchemaChangeReport{}, fmt.Errorf("could not parse generated schema: %w", err)
+	}
+	return compareSchemas(previous, current), nil
+}
+
+// keepDeprecatedFields returns the fields of the previous schema which are not generated anymore and are kept
+// deprecated in the types of the models during the grace period
+func keepDeprecatedFields(
+	previous, current *ast.SchemaDocument,
+	modelNames map[string]bool,
+	evolution SchemaEvolution,
+	now time.Time,
+	logger *slog.Logger,
+) map[string][]deprecatedField {
+	gracePeriod := evolution.GracePeriod
+	if gracePeriod == 0 {
+		gracePeriod = defaultGracePeriod
+	}
+	deprecatedFields := map[string][]deprecatedField{}
+	for _, definition := range previous.Definitions {
+		currentDefinition := current.Definitions.ForName(definition.Name)
+		if currentDefinition == nil || currentDefinition.Kind != ast.Object || !modelNames[definition.Name] {
+			continue
+		}
+		for _, field := range definition.Fields {
+			if currentDefinition.Fields.ForName(field.Name) != nil {
+				continue
+			}
+			since, ok := cache.DeprecatedSince(field)
+			if !ok {
+				since = now
+			}
+			if now.After(since.Add(gracePeriod)) {
+				logger.Info("grace period of deprecated field is over", "field", definition.Name+"."+field.Name,
+					"deprecatedSince", since.Format("2006-01-02"))
+				continue
+			}
+
+			kept := deprecatedField{Name: field.Name}
+			if renamedTo := evolution.Renames[definition.Name+"."+field.Name]; renamedTo != "" {
+				renamedField := currentDefinition.Fields.ForName(renamedTo)
+				if renamedField == nil {
+					logger.Warn("renamed field does not exist", "field", definition.Name+"."+field.Name,
+						"renamedTo", renamedTo)
+					continue
+				}
+				kept.Type = renamedField.Type.String()
+				kept.Reason = cache.DeprecationReasonRenamed(renamedTo, since)
+			} else {
+				// a field of another type can not be kept when that type is removed too
+				if current.Definitions.ForName(field.Type.Name()) == nil && !isBuiltInScalar(field.Type.Name()) {
+					continue
+				}
+				// a removed column returns null
+				nullableType := *field.Type
+				nullableType.NonNull = false
+				kept.Type = nullableType.String()
+				kept.Reason = cache.DeprecationReasonRemoved(since)
+			}
+			deprecatedFields[definition.Name] = append(deprecatedFields[definition.Name], kept)
+		}
+	}
+	return deprecatedFields
+}
+
+func isBuiltInScalar(name string) bool {
+	switch name {
+	case "String", "Int", "Float", "Boolean", "ID":
+		return true
+	}
+	return false
+}
+
+// checkSchemaEvolution reports the changes and returns an error when breaking changes are not allowed
+func checkSchemaEvolution(evolution SchemaEvolution, report SchemaChangeReport, logger *slog.Logger) error {
+	if evolution.Report != nil {
+		evolution.Report(report)
+	}
+	for _, change := range report.Changes {
+		logger.Info("schema change", "kind", change.Kind, "severity", change.Severity, "type", change.Type,
+			"field", change.Field, "message", change.Message)
+	}
+	if breaking := report.Breaking(); len(breaking) > 0 && !evolution.AllowBreakingChanges {
+		return fmt.Errorf("schema contains %d breaking changes, set AllowBreakingChanges to write it anyway:\n%v",
+			len(breaking), SchemaChangeReport{Changes: breaking})
+	}
+	return nil
+}
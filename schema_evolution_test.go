@@ -0,0 +1,47 @@
+package gbgen
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const previousEvolutionSchema = `
+type User implements Node {
+  id: ID!
+  lastName: String!
+  bio: String!
+  organization: Organization!
+  nickname: String @deprecated(reason: "Removed from the database on 2026-01-01")
+  avatar: String @deprecated(reason: "Removed from the database on 2026-10-01")
+}
+type Organization implements Node { id: ID! }
+`
+
+const currentEvolutionSchema = `
+type User implements Node {
+  id: ID!
+  familyName: String!
+}
+`
+
+func TestKeepDeprecatedFields(t *testing.T) {
+	deprecatedFields := keepDeprecatedFields(
+		mustParseSchema(t, previousEvolutionSchema),
+		mustParseSchema(t, currentEvolutionSchema),
+		map[string]bool{"User": true},
+		SchemaEvolution{Renames: map[string]string{"User.lastName": "familyName"}},
+		time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		discardLogger,
+	)
+
+	// nickname is past the grace period and organization can not be kept without its type
+	want := map[string][]deprecatedField{"User": {
+		{Name: "lastName", Type: "String!", Reason: "Use familyName instead, renamed in the database on 2026-10-19"},
+		{Name: "bio", Type: "String", Reason: "Removed from the database on 2026-10-19"},
+		{Name: "avatar", Type: "String", Reason: "Removed from the database on 2026-10-01"},
+	}}
+	if !reflect.DeepEqual(deprecatedFields, want) {
+		t.Errorf("got deprecated fields %+v, want %+v", deprecatedFields, want)
+	}
+}
@@ -4,17 +4,24 @@ package gbgen
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/web-ridge/gqlgen-sqlboiler/v3/structs"
 
-	"github.com/rs/zerolog/log"
 	"github.com/web-ridge/gqlgen-sqlboiler/v3/cache"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/logging"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/naming"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/templates"
 
 	"github.com/iancoleman/strcase"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
 )
 
 const (
@@ -35,16 +42,36 @@ type SchemaConfig struct {
 	HookChangeField     func(model *SchemaModel, field *SchemaField)
 	HookChangeFields    func(model *SchemaModel, fields []*SchemaField, parenType ParentType) []*SchemaField
 	HookChangeModel     func(model *SchemaModel)
+	// Pagination makes first optional in list queries with the default page size of the model as default value
+	Pagination *PaginationConfig
+	// ConstraintDirectives adds @constraint(maxLength: n, unique: true) to input fields when the boiler cache has a schema dump
+	ConstraintDirectives bool
+	// Logger is the logger of the schema generator, the logger of the boiler cache when nil
+	Logger *slog.Logger
+	// existingDescriptions are the descriptions in the schema on disk by type name and type.field name, these are kept
+	// when merging the schema so descriptions which are written by hand are not lost
+	existingDescriptions map[string]string
+	// deprecatedFields are the fields of removed or renamed columns which are kept by type name, see SchemaEvolution
+	deprecatedFields map[string][]deprecatedField
 }
 
 type SchemaGenerateConfig struct {
 	MergeSchema bool
+	// DryRun compares the schema with the schema on disk instead of writing it
+	DryRun *templates.DryRun
+	// Evolution keeps the fields of removed columns deprecated and reports breaking changes before writing the schema
+	Evolution *SchemaEvolution
 }
 
 type SchemaModel struct {
+	// Name is the name of the GraphQL type, the name of the sqlboiler model unless the naming strategy changes it
 	Name   string
 	IsView bool
 	Fields []*SchemaField
+	// BoilerModel is the sqlboiler model of the type
+	BoilerModel *structs.BoilerModel
+	// Description is written above the type, it is the comment of the table by default
+	Description string
 }
 
 type SchemaField struct {
@@ -64,6 +91,8 @@ type SchemaField struct {
 	SkipBatchCreate      bool
 	InputDirectives      []string
 	Directives           []string
+	// Description is written above the field and its input fields, it is the comment of the column by default
+	Description string
 }
 
 func NewSchemaField(name string, typ string, boilerField *structs.BoilerField) *SchemaField {
@@ -76,6 +105,7 @@ func NewSchemaField(name string, typ string, boilerField *structs.BoilerField) *
 		InputBatchUpdateType: typ,
 		InputBatchCreateType: typ,
 		BoilerField:          boilerField,
+		Description:          boilerField.Description,
 	}
 }
 
@@ -107,26 +137,72 @@ const (
 	ParentTypeBatchCreate ParentType = "BatchCreate"
 )
 
+// logger returns the logger of the config, the logger of the boiler cache or else the default logger
+func (c SchemaConfig) logger() *slog.Logger {
+	logger := c.Logger
+	if logger == nil && c.BoilerCache != nil {
+		logger = c.BoilerCache.Logger
+	}
+	return logging.OrDefault(logger)
+}
+
 func SchemaWrite(config SchemaConfig, outputFile string, generateOptions SchemaGenerateConfig) error {
+	logger := config.logger()
+	if fileExists(outputFile) && generateOptions.MergeSchema {
+		existingDescriptions, err := readSchemaDescriptions(outputFile)
+		if err != nil {
+			logger.Warn("could not read the descriptions of the existing schema", "error", err)
+		}
+		config.existingDescriptions = existingDescriptions
+	}
+
+	if generateOptions.Evolution != nil {
+		report, err := evolveSchema(&config, outputFile, *generateOptions.Evolution)
+		if err != nil {
+			logger.Error("could not compare schema with the previous schema", "error", err)
+			return err
+		}
+		if err := checkSchemaEvolution(*generateOptions.Evolution, report, logger); err != nil {
+			return err
+		}
+	}
+
 	// Generate schema based on config
 	schema := SchemaGet(config)
 
+	if generateOptions.DryRun != nil {
+		content, err := renderSchemaFile(schema, outputFile, generateOptions.MergeSchema, logger)
+		if err != nil {
+			logger.Error("could not render schema", "error", err)
+			return err
+		}
+		return generateOptions.DryRun.Compare(outputFile, content)
+	}
+
+	if evolution := generateOptions.Evolution; evolution != nil && evolution.PreviousSchemaFile != "" &&
+		evolution.PreviousSchemaFile != outputFile {
+		if err := writeContentToFile(schema, evolution.PreviousSchemaFile); err != nil {
+			logger.Error("could not write generated schema", "error", err)
+			return err
+		}
+	}
+
 	// TODO: Write schema to the configured location
 	if fileExists(outputFile) && generateOptions.MergeSchema {
-		if err := mergeContentInFile(schema, outputFile); err != nil {
-			log.Err(err).Msg("Could not write schema to disk")
+		if err := mergeContentInFile(schema, outputFile, logger); err != nil {
+			logger.Error("could not write schema to disk", "error", err)
 			return err
 		}
 	} else {
-		log.Debug().Int("bytes", len(schema)).Str("file", outputFile).Msg("write GraphQL schema to disk")
+		logger.Debug("write GraphQL schema to disk", "bytes", len(schema), "file", outputFile)
 		if err := writeContentToFile(schema, outputFile); err != nil {
-			log.Err(err).Msg("Could not write schema to disk")
+			logger.Error("could not write schema to disk", "error", err)
 			return err
 		}
-		log.Debug().Msg("formatting GraphQL schema")
+		logger.Debug("formatting GraphQL schema")
 
 		err := formatFile(outputFile)
-		log.Debug().Msg("formatted GraphQL schema")
+		logger.Debug("formatted GraphQL schema")
 		return err
 	}
 
@@ -141,6 +217,23 @@ func getDirectivesAsString(va []string) string {
 	return strings.Join(a, " ")
 }
 
+func getConstraintDirective(config SchemaConfig, field *SchemaField) string {
+	if !config.ConstraintDirectives || field.BoilerField == nil || field.Type == "ID" {
+		return ""
+	}
+	var arguments []string
+	if field.BoilerField.MaxLength > 0 {
+		arguments = append(arguments, fmt.Sprintf("maxLength: %d", field.BoilerField.MaxLength))
+	}
+	if field.BoilerField.IsUnique {
+		arguments = append(arguments, "unique: true")
+	}
+	if len(arguments) == 0 {
+		return ""
+	}
+	return " @constraint(" + strings.Join(arguments, ", ") + ")"
+}
+
 //nolint:gocognit,gocyclo
 func SchemaGet(
 	config SchemaConfig,
@@ -149,7 +242,8 @@ func SchemaGet(
 
 	// Parse structs and their fields based on the sqlboiler model directory
 
-	models := executeHooksOnModels(boilerModelsToModels(config.BoilerCache.BoilerModels), config)
+	strategy := config.BoilerCache.Naming
+	models := executeHooksOnModels(boilerModelsToModels(config.BoilerCache.BoilerModels, strategy), config)
 
 	fullDirectives := make([]string, len(config.Directives))
 	for i, defaultDirective := range config.Directives {
@@ -158,6 +252,11 @@ func SchemaGet(
 	}
 	w.br()
 
+	if config.ConstraintDirectives {
+		w.l("directive @constraint(maxLength: Int, unique: Boolean) on INPUT_FIELD_DEFINITION")
+		w.br()
+	}
+
 	joinedDirectives := strings.Join(fullDirectives, " ")
 
 	w.l(`schema {`)
@@ -193,9 +292,10 @@ func SchemaGet(
 		w.l(fmt.Sprintf(enumFilterHelper, enum.Name))
 
 		//	enum UserRole { ADMIN, USER }
+		w.description("", getDescription(config, enum.Name, enum.Description))
 		w.l("enum " + enum.Name + " {")
 		for _, v := range enum.Values {
-			w.tl(strcase.ToScreamingSnake(strings.TrimPrefix(v.Name, enum.Name)))
+			w.tl(strategy.EnumValueName(enum.Name, v.Name))
 		}
 		w.l("}")
 
@@ -235,6 +335,7 @@ func SchemaGet(
 		// 	organization: Organization!
 		// }
 
+		w.description("", getDescription(config, model.Name, model.Description))
 		w.l("type " + model.Name + " implements Node {")
 
 		for _, field := range enhanceFields(config, model, model.Fields, ParentTypeNormal) {
@@ -243,15 +344,20 @@ func SchemaGet(
 			// organizationID is clutter in your scheme
 			// you only want Organization and OrganizationID should be skipped
 			if field.BoilerField.IsRelation {
+				w.description(indent, getDescription(config, model.Name+"."+getRelationName(model, field, strategy), field.Description))
 				w.tl(
-					getRelationName(field) + ": " +
-						getFinalFullTypeWithRelation(field, ParentTypeNormal) + directives,
+					getRelationName(model, field, strategy) + ": " +
+						getFinalFullTypeWithRelation(field, ParentTypeNormal, strategy) + directives,
 				)
 			} else {
 				fullType := getFinalFullType(field, ParentTypeNormal)
+				w.description(indent, getDescription(config, model.Name+"."+field.Name, field.Description))
 				w.tl(field.Name + ": " + fullType + directives)
 			}
 		}
+		for _, field := range config.deprecatedFields[model.Name] {
+			w.tl(field.Name + ": " + field.Type + " @deprecated(reason: " + strconv.Quote(field.Reason) + ")")
+		}
 		w.l("}")
 
 		w.br()
@@ -316,9 +422,11 @@ func SchemaGet(
 			if field.BoilerField.IsRelation {
 
 				// Support filtering in relationships (at least schema wise)
-				relationName := getRelationName(field)
-				w.tl(relationName + ": " + field.BoilerField.Relationship.Name + "Where" + directives)
+				relationName := getRelationName(model, field, strategy)
+				w.description(indent, getDescription(config, model.Name+"Where."+relationName, field.Description))
+				w.tl(relationName + ": " + strategy.TypeName(field.BoilerField.Relationship.Name) + "Where" + directives)
 			} else {
+				w.description(indent, getDescription(config, model.Name+"Where."+field.Name, field.Description))
 				w.tl(field.Name + ": " + getFilterType(field) + "Filter" + directives)
 			}
 		}
@@ -332,16 +440,22 @@ func SchemaGet(
 
 	w.l("type Query {")
 	w.tl("node(id: ID!): Node" + joinedDirectives)
+	w.tl("nodes(ids: [ID!]!): [Node]!" + joinedDirectives)
 
 	for _, model := range models {
 		// single structs
 		w.tl(strcase.ToLowerCamel(model.Name) + "(id: ID!): " + model.Name + "!" + joinedDirectives)
 
 		// lists
-		modelPluralName := cache.Plural(model.Name)
+		modelPluralName := strategy.Plural(model.Name)
+
+		first := "first: Int!"
+		if config.Pagination != nil {
+			first = fmt.Sprintf("first: Int = %d", config.Pagination.For(model.boilerName()).Default)
+		}
 
 		arguments := []string{
-			"first: Int!",
+			first,
 			"after: String",
 			"ordering: [" + model.Name + "Ordering!]",
 			"filter: " + model.Name + "Filter",
@@ -363,7 +477,7 @@ func SchemaGet(
 
 			filteredFields := fieldsWithout(model.Fields, config.SkipInputFields)
 
-			modelPluralName := cache.Plural(model.Name)
+			modelPluralName := strategy.Plural(model.Name)
 			// input UserCreateInput {
 			// 	firstName: String!
 			// 	lastName: String
@@ -388,7 +502,8 @@ func SchemaGet(
 				}
 				directives := getDirectivesAsString(field.InputDirectives)
 				fullType := getFinalFullType(field, ParentTypeCreate)
-				w.tl(field.Name + ": " + fullType + directives)
+				w.description(indent, getDescription(config, model.Name+"CreateInput."+field.Name, field.Description))
+				w.tl(field.Name + ": " + fullType + directives + getConstraintDirective(config, field))
 			}
 			w.l("}")
 
@@ -417,7 +532,8 @@ func SchemaGet(
 					continue
 				}
 				directives := getDirectivesAsString(field.InputDirectives)
-				w.tl(field.Name + ": " + getFinalFullType(field, ParentTypeUpdate) + directives)
+				w.description(indent, getDescription(config, model.Name+"UpdateInput."+field.Name, field.Description))
+				w.tl(field.Name + ": " + getFinalFullType(field, ParentTypeUpdate) + directives + getConstraintDirective(config, field))
 			}
 			w.l("}")
 
@@ -499,7 +615,7 @@ func SchemaGet(
 			if model.IsView {
 				continue
 			}
-			modelPluralName := cache.Plural(model.Name)
+			modelPluralName := strategy.Plural(model.Name)
 
 			// create single
 			// e.g createUser(input: UserInput!): UserPayload!
@@ -549,6 +665,10 @@ func getFilterType(field *SchemaField) string {
 	if boilerType == "null.Time" || boilerType == "time.Time" {
 		return "TimeUnix"
 	}
+	// an array of enums is filtered on the values it contains
+	if field.BoilerField.IsEnumArray {
+		return field.BoilerField.Enum.Name
+	}
 	return field.Type
 }
 
@@ -584,13 +704,15 @@ func getFullType(fieldType string, isArray bool, isRequired bool) string {
 	return gType
 }
 
-func boilerModelsToModels(boilerModels []*structs.BoilerModel) []*SchemaModel {
+func boilerModelsToModels(boilerModels []*structs.BoilerModel, strategy naming.Strategy) []*SchemaModel {
 	a := make([]*SchemaModel, len(boilerModels))
 	for i, boilerModel := range boilerModels {
 		a[i] = &SchemaModel{
-			Name:   boilerModel.Name,
-			Fields: boilerFieldsToFields(boilerModel.Fields),
-			IsView: boilerModel.IsView,
+			Name:        strategy.TypeName(boilerModel.Name),
+			Fields:      boilerFieldsToFields(boilerModel, strategy),
+			BoilerModel: boilerModel,
+			IsView:      boilerModel.IsView,
+			Description: boilerModel.Description,
 		}
 	}
 	return a
@@ -624,28 +746,36 @@ func executeHooksOnModels(models []*SchemaModel, config SchemaConfig) []*SchemaM
 	return a
 }
 
-func boilerFieldsToFields(boilerFields []*structs.BoilerField) []*SchemaField {
-	fields := make([]*SchemaField, len(boilerFields))
-	for i, boilerField := range boilerFields {
-		fields[i] = boilerFieldToField(boilerField)
+func boilerFieldsToFields(boilerModel *structs.BoilerModel, strategy naming.Strategy) []*SchemaField {
+	fields := make([]*SchemaField, len(boilerModel.Fields))
+	for i, boilerField := range boilerModel.Fields {
+		fields[i] = boilerFieldToField(boilerModel, boilerField, strategy)
 	}
 	return fields
 }
 
-func getRelationName(schemaField *SchemaField) string {
-	return strcase.ToLowerCamel(schemaField.BoilerField.RelationshipName)
+func getRelationName(model *SchemaModel, schemaField *SchemaField, strategy naming.Strategy) string {
+	return strategy.RelationName(model.boilerName(), schemaField.BoilerField.RelationshipName)
+}
+
+// boilerName returns the name of the sqlboiler model which is used in the config e.g. for page sizes and renames
+func (m *SchemaModel) boilerName() string {
+	if m.BoilerModel != nil {
+		return m.BoilerModel.Name
+	}
+	return m.Name
 }
 
 func getAlwaysOptional(parentType ParentType) bool {
 	return parentType == ParentTypeUpdate || parentType == ParentTypeWhere || parentType == ParentTypeBatchUpdate
 }
 
-func getFinalFullTypeWithRelation(schemaField *SchemaField, parentType ParentType) string {
+func getFinalFullTypeWithRelation(schemaField *SchemaField, parentType ParentType, strategy naming.Strategy) string {
 	boilerField := schemaField.BoilerField
 	alwaysOptional := getAlwaysOptional(parentType)
 
 	if boilerField.Relationship != nil {
-		relationType := boilerField.Relationship.Name
+		relationType := strategy.TypeName(boilerField.Relationship.Name)
 		if alwaysOptional {
 			return getFullType(
 				relationType,
@@ -692,34 +822,18 @@ func getFieldType(schemaField *SchemaField, parentType ParentType) string {
 	}
 }
 
-func boilerFieldToField(boilerField *structs.BoilerField) *SchemaField {
+func boilerFieldToField(boilerModel *structs.BoilerModel, boilerField *structs.BoilerField,
+	strategy naming.Strategy) *SchemaField {
 	t := toGraphQLType(boilerField)
-	return NewSchemaField(toGraphQLName(boilerField.Name), t, boilerField)
-}
-
-func toGraphQLName(fieldName string) string {
-	graphqlName := fieldName
-
-	// Golang ID to Id the right way
-	// Primary key
-	if graphqlName == "ID" {
-		graphqlName = "id"
-	}
-
-	if graphqlName == "URL" {
-		graphqlName = "url"
-	}
-
-	// e.g. OrganizationID, TODO: more robust solution?
-	graphqlName = strings.Replace(graphqlName, "ID", "Id", -1)
-	graphqlName = strings.Replace(graphqlName, "URL", "Url", -1)
-
-	return strcase.ToLowerCamel(graphqlName)
+	return NewSchemaField(strategy.FieldName(boilerModel.Name, boilerField.Name), t, boilerField)
 }
 
 func toGraphQLType(boilerField *structs.BoilerField) string {
 	lowerBoilerType := strings.ToLower(boilerField.Type)
 
+	if boilerField.IsEnumArray {
+		return "[" + boilerField.Enum.Name + "!]"
+	}
 	if boilerField.IsEnum {
 		return boilerField.Enum.Name
 	}
@@ -764,7 +878,7 @@ func fieldsWithout(fields []*SchemaField, skipFieldNames []string) []*SchemaFiel
 	return filteredFields
 }
 
-func mergeContentInFile(content, outputFile string) error {
+func mergeContentInFile(content, outputFile string, logger *slog.Logger) error {
 	baseFile := filenameWithoutExtension(outputFile) +
 		"-empty" +
 		getFilenameExtension(outputFile)
@@ -795,7 +909,7 @@ func mergeContentInFile(content, outputFile string) error {
 	args := []string{baseFile}
 	out, err := exec.Command(name, args...).Output()
 	if err != nil {
-		log.Err(err).Str("name", name).Str("args", strings.Join(args, " ")).Msg("merging failed")
+		logger.Error("merging failed", "error", err, "name", name, "args", strings.Join(args, " "))
 		return fmt.Errorf("merging failed %v: %v", err, out)
 	}
 
@@ -804,13 +918,13 @@ func mergeContentInFile(content, outputFile string) error {
 	args = []string{"merge-file", outputFile, baseFile, newOutputFile}
 	out, err = exec.Command(name, args...).Output()
 	if err != nil {
-		log.Err(err).Str("name", name).Str("args", strings.Join(args, " ")).Msg("executing command failed")
+		logger.Error("executing command failed", "error", err, "name", name, "args", strings.Join(args, " "))
 
 		// remove base file
 		_ = os.Remove(baseFile)
 		return fmt.Errorf("merging failed or had conflicts %v: %v", err, out)
 	}
-	log.Info().Msg("merging done without conflicts")
+	logger.Info("merging done without conflicts")
 
 	// remove files
 	_ = os.Remove(baseFile)
@@ -818,6 +932,41 @@ func mergeContentInFile(content, outputFile string) error {
 	return nil
 }
 
+// renderSchemaFile merges or formats the schema in a temporary directory so the result is the same as the file
+// SchemaWrite would write, without leaving files next to outputFile
+func renderSchemaFile(schema, outputFile string, mergeSchema bool, logger *slog.Logger) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "gqlgen-sqlboiler-schema")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	// the name of the output file is kept so prettier detects the parser by its extension
+	dryRunFile := filepath.Join(dir, filepath.Base(outputFile))
+
+	if fileExists(outputFile) && mergeSchema {
+		existing, err := os.ReadFile(outputFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %v: %v", outputFile, err)
+		}
+		if err := writeContentToFile(string(existing), dryRunFile); err != nil {
+			return nil, err
+		}
+		if err := mergeContentInFile(schema, dryRunFile, logger); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := writeContentToFile(schema, dryRunFile); err != nil {
+			return nil, err
+		}
+		if err := formatFile(dryRunFile); err != nil {
+			return nil, err
+		}
+	}
+	return os.ReadFile(dryRunFile)
+}
+
 func getFilenameExtension(fn string) string {
 	return path.Ext(fn)
 }
@@ -843,24 +992,7 @@ func formatFile(filename string) error {
 }
 
 func writeContentToFile(content string, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("could not write %v to disk: %v", filename, err)
-	}
-
-	// Close file if this functions returns early or at the end
-	defer func() {
-		closeErr := file.Close()
-		if closeErr != nil {
-			log.Err(closeErr).Msg("error while closing file")
-		}
-	}()
-
-	if _, err := file.WriteString(content); err != nil {
-		return fmt.Errorf("could not write content to file %v: %v", filename, err)
-	}
-
-	return nil
+	return templates.WriteFile(filename, []byte(content))
 }
 
 type SimpleWriter struct {
@@ -879,6 +1011,54 @@ func (sw *SimpleWriter) tl(v string) {
 	sw.s.WriteString(indent + v + lineBreak)
 }
 
+// description writes a block string above a type or field like prettier formats it
+func (sw *SimpleWriter) description(indentation string, v string) {
+	if v == "" {
+		return
+	}
+	sw.l(indentation + `"""`)
+	for _, line := range strings.Split(strings.ReplaceAll(v, `"""`, `\"""`), "\n") {
+		sw.l(strings.TrimRight(indentation+line, " "))
+	}
+	sw.l(indentation + `"""`)
+}
+
+// getDescription returns the description from the database and falls back to the description in the schema on disk
+// e.g. key User or User.firstName
+func getDescription(config SchemaConfig, key string, description string) string {
+	if description != "" {
+		return description
+	}
+	return config.existingDescriptions[key]
+}
+
+// readSchemaDescriptions returns the descriptions of the types and their fields in a schema by type name and
+// type.field name
+func readSchemaDescriptions(schemaFile string) (map[string]string, error) {
+	content, err := os.ReadFile(schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	document, err := parser.ParseSchema(&ast.Source{Name: schemaFile, Input: string(content)})
+	if err != nil {
+		return nil, err
+	}
+	descriptions := map[string]string{}
+	for _, definitions := range []ast.DefinitionList{document.Definitions, document.Extensions} {
+		for _, definition := range definitions {
+			if definition.Description != "" {
+				descriptions[definition.Name] = definition.Description
+			}
+			for _, field := range definition.Fields {
+				if field.Description != "" {
+					descriptions[definition.Name+"."+field.Name] = field.Description
+				}
+			}
+		}
+	}
+	return descriptions, nil
+}
+
 const enumFilterHelper = `
 input %[1]vFilter {
 	isNull: Boolean
@@ -0,0 +1,38 @@
+package gbgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSchemaDescriptions(t *testing.T) {
+	w := &SimpleWriter{}
+	w.description("", "Users of the app\nwith a \"\"\"quoted\"\"\" part")
+	w.l("type User {")
+	w.description(indent, getDescription(SchemaConfig{}, "User.id", ""))
+	w.tl("id: ID!")
+	w.description(indent, getDescription(SchemaConfig{
+		existingDescriptions: map[string]string{"User.email": "Written by hand"},
+	}, "User.email", ""))
+	w.tl("email: String!")
+	w.l("}")
+
+	schemaFile := filepath.Join(t.TempDir(), "schema.graphql")
+	if err := os.WriteFile(schemaFile, []byte(w.s.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	descriptions, err := readSchemaDescriptions(schemaFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]string{
+		"User":       "Users of the app\nwith a \"\"\"quoted\"\"\" part",
+		"User.id":    "",
+		"User.email": "Written by hand",
+	} {
+		if got := descriptions[k]; got != want {
+			t.Errorf("%v: got %q, want %q", k, got, want)
+		}
+	}
+}
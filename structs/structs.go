@@ -101,11 +101,21 @@ type Enum struct {
 	BoilerEnum    *BoilerEnum
 }
 
+// DatabaseType returns the Go type of the values of the enum in the database, string or int
+func (e *Enum) DatabaseType() string {
+	if e.BoilerEnum != nil && e.BoilerEnum.DatabaseType != "" {
+		return e.BoilerEnum.DatabaseType
+	}
+	return "string"
+}
+
 type EnumValue struct {
 	Description     string
 	Name            string
 	NameLower       string
 	BoilerEnumValue *BoilerEnumValue
+	// BoilerFieldName is the sqlboiler field which is sorted on by a value of a {{Model}}Sort enum
+	BoilerFieldName string
 }
 
 type BoilerModel struct {
@@ -117,33 +127,72 @@ type BoilerModel struct {
 	HasPrimaryStringID bool
 	HasDeletedAt       bool
 	IsView             bool
+	// Description is the comment of the table in the database
+	Description string
 }
 
 type BoilerField struct {
-	Name             string
-	PluralName       string
-	Type             string
-	IsForeignKey     bool
-	IsRequired       bool
-	IsArray          bool
-	IsEnum           bool
+	Name         string
+	PluralName   string
+	Type         string
+	IsForeignKey bool
+	IsRequired   bool
+	IsArray      bool
+	IsEnum       bool
+	// IsEnumArray is an array column of enum values e.g. a Postgres enum[] column
+	IsEnumArray      bool
 	IsRelation       bool
 	InTable          bool
 	InTableNotID     bool
 	Enum             BoilerEnum
 	RelationshipName string
 	Relationship     *BoilerModel
+	// database constraints, only filled when a schema dump is added to the boiler cache
+	MaxLength int
+	Precision int
+	Scale     int
+	IsUnique  bool
+	// Description is the comment of the column in the database
+	Description string
 }
 
 type BoilerEnum struct {
-	Name          string
+	Name string
+	// ModelName and ModelFieldKey are the first column which uses the enum
 	ModelName     string
 	ModelFieldKey string
-	Values        []*BoilerEnumValue
+	// Fields are all columns which use the enum, the same enum type can be used by several tables
+	Fields []*BoilerEnumField
+	Values []*BoilerEnumValue
+	// DatabaseType is the Go type of the values in the database, string or int
+	DatabaseType string
+	// IsMapping is true for enums which are not generated by sqlboiler but added with an enum mapping
+	IsMapping bool
+	// Description is the comment of the enum type in the database
+	Description string
+}
+
+// HasArrayField returns true when the enum is used by an array column
+func (e *BoilerEnum) HasArrayField() bool {
+	for _, field := range e.Fields {
+		if field.IsArray {
+			return true
+		}
+	}
+	return false
+}
+
+type BoilerEnumField struct {
+	ModelName string
+	FieldKey  string
+	IsArray   bool
 }
 
 type BoilerEnumValue struct {
 	Name string
+	// Literal is the Go literal of the database value of a mapped enum e.g. "admin" or 1, sqlboiler enums use
+	// the constant Name instead
+	Literal string
 }
 
 type BoilerType struct {
@@ -0,0 +1,88 @@
+package gbgen
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed template_files/*.gotpl
+var templateFiles embed.FS
+
+const (
+	templateExtension = ".gotpl"
+	blocksExtension   = ".blocks" + templateExtension
+)
+
+// templateSource is a template with the {{define}} blocks which replace parts of it
+type templateSource struct {
+	Template string
+	Blocks   string
+}
+
+// loadTemplate returns the embedded template with the given name. A template with the same name in templateDirectory
+// replaces it completely and the {{define}} blocks of <name>.blocks.gotpl replace its {{block}} blocks.
+func loadTemplate(templateDirectory string, name string) (templateSource, error) {
+	var source templateSource
+
+	template, err := readTemplateOverride(templateDirectory, name)
+	if err != nil {
+		return source, err
+	}
+	if template == "" {
+		content, err := templateFiles.ReadFile("template_files/" + name)
+		if err != nil {
+			return source, fmt.Errorf("could not read template file %v: %w", name, err)
+		}
+		template = string(content)
+	}
+	source.Template = template
+
+	source.Blocks, err = readTemplateOverride(templateDirectory, strings.TrimSuffix(name, templateExtension)+blocksExtension)
+	return source, err
+}
+
+func readTemplateOverride(templateDirectory string, name string) (string, error) {
+	if templateDirectory == "" {
+		return "", nil
+	}
+	content, err := os.ReadFile(filepath.Join(templateDirectory, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read template override %v: %w", name, err)
+	}
+	return string(content), nil
+}
+
+// extraTemplates returns the templates of templateDirectory which are not part of the generator, they are rendered
+// next to the generated helpers e.g. generated_audit.gotpl becomes generated_audit.go
+func extraTemplates(templateDirectory string) ([]string, error) {
+	if templateDirectory == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(templateDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("could not read template directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, templateExtension) || strings.HasSuffix(name, blocksExtension) {
+			continue
+		}
+		if _, err := fs.Stat(templateFiles, "template_files/"+name); err == nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
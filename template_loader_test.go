@@ -0,0 +1,85 @@
+package gbgen
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/templates"
+)
+
+func TestEmbeddedTemplatesParse(t *testing.T) {
+	names, err := fs.Glob(templateFiles, "template_files/*"+templateExtension)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		source, err := loadTemplate("", filepath.Base(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// executing without data fails, parse errors are returned before that
+		_, err = templates.GetTemplateContent(templates.Options{Name: name, Template: source.Template})
+		if err != nil && strings.HasPrefix(err.Error(), "parse") {
+			t.Errorf("%v: %v", name, err)
+		}
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"generated_sort.gotpl":            "override",
+		"generated_resolver.blocks.gotpl": `{{ define "updateResolver" }}custom{{ end }}`,
+		"generated_audit.gotpl":           "extra",
+		"README.md":                       "not a template",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		template   string
+		isEmbedded bool
+		blocks     string
+	}{
+		{name: "generated_sort.gotpl", template: "override"},
+		{name: "generated_resolver.gotpl", isEmbedded: true, blocks: files["generated_resolver.blocks.gotpl"]},
+		{name: "generated_crud.gotpl", isEmbedded: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := loadTemplate(dir, tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.isEmbedded {
+				embedded, err := templateFiles.ReadFile("template_files/" + tt.name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tt.template = string(embedded)
+			}
+			if source.Template != tt.template {
+				t.Errorf("template = %.40q, want %.40q", source.Template, tt.template)
+			}
+			if source.Blocks != tt.blocks {
+				t.Errorf("blocks = %q, want %q", source.Blocks, tt.blocks)
+			}
+		})
+	}
+
+	extra, err := extraTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"generated_audit.gotpl"}; !reflect.DeepEqual(extra, want) {
+		t.Errorf("extraTemplates() = %v, want %v", extra, want)
+	}
+}
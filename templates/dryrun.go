@@ -0,0 +1,86 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// FileDiff is the unified diff between a file on disk and the generated content
+type FileDiff struct {
+	FileName string
+	Diff     string
+}
+
+// DryRun collects the differences between the generated files and the files on disk instead of writing them, e.g. to
+// fail CI when someone forgot to regenerate after a migration or to preview a generator upgrade
+type DryRun struct {
+	mu    sync.Mutex
+	diffs []FileDiff
+}
+
+func NewDryRun() *DryRun {
+	return &DryRun{}
+}
+
+// Compare records the difference between the file on disk and content, a missing file is compared as empty
+func (d *DryRun) Compare(fileName string, content []byte) error {
+	existing, err := os.ReadFile(fileName)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not read %v to compare: %w", fileName, err)
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(existing)),
+		B:        difflib.SplitLines(string(content)),
+		FromFile: "a/" + fileName,
+		ToFile:   "b/" + fileName,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("could not diff %v: %w", fileName, err)
+	}
+	if diff == "" {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.diffs = append(d.diffs, FileDiff{FileName: fileName, Diff: diff})
+	return nil
+}
+
+// Changed returns true if at least one generated file differs from disk
+func (d *DryRun) Changed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.diffs) > 0
+}
+
+// Diffs returns the differences sorted by file name
+func (d *DryRun) Diffs() []FileDiff {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	diffs := make([]FileDiff, len(d.diffs))
+	copy(diffs, d.diffs)
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].FileName < diffs[j].FileName
+	})
+	return diffs
+}
+
+// Print writes the unified diffs of all changed files to w
+func (d *DryRun) Print(w io.Writer) error {
+	for _, diff := range d.Diffs() {
+		if _, err := io.WriteString(w, diff.Diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
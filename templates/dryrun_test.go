@@ -0,0 +1,55 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDryRunCompare(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.go")
+	if err := os.WriteFile(existing, []byte("package a\n\nvar a = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		diff     []string
+	}{
+		{"unchanged", existing, "package a\n\nvar a = 1\n", nil},
+		{"changed", existing, "package a\n\nvar a = 2\n", []string{"-var a = 1", "+var a = 2"}},
+		{"new file", filepath.Join(dir, "new.go"), "package a\n", []string{"+package a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dryRun := NewDryRun()
+			if err := dryRun.Compare(tt.fileName, []byte(tt.content)); err != nil {
+				t.Fatal(err)
+			}
+			if dryRun.Changed() != (tt.diff != nil) {
+				t.Fatalf("Changed() = %v, want %v", dryRun.Changed(), tt.diff != nil)
+			}
+			var out strings.Builder
+			if err := dryRun.Print(&out); err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range tt.diff {
+				if !strings.Contains(out.String(), line+"\n") {
+					t.Errorf("diff does not contain %q:\n%v", line, out.String())
+				}
+			}
+		})
+	}
+
+	content, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "package a\n\nvar a = 1\n" {
+		t.Error("dry run should not write the file")
+	}
+}
@@ -2,19 +2,22 @@ package templates
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/format"
 	"go/parser"
 	"go/printer"
+	"go/scanner"
 	"go/token"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/template"
 
-	"github.com/rs/zerolog/log"
-
 	"github.com/iancoleman/strcase"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/customization"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/naming"
 
 	"golang.org/x/tools/imports"
 
@@ -22,6 +25,8 @@ import (
 )
 
 type Options struct {
+	// Name of the template used in error messages e.g. generated_convert.gotpl
+	Name string
 	// PackageName is a helper that specifies the package header declaration.
 	// In other words, when you write the template you don't need to specify `package X`
 	// at the top of the file. By providing PackageName in the Options, the Render
@@ -34,97 +39,241 @@ type Options struct {
 	Template string
 	// UserDefinedFunctions is used to rewrite in the the file so we can use custom functions
 	// The struct is still available for use in private but will be rewritten to
-	// a private function with original in front of it
-	UserDefinedFunctions []string
+	// a private function with original in front of it. Functions are matched on receiver and name, the normalized
+	// signature of the user defined function has to be the same as the generated one.
+	UserDefinedFunctions []customization.Function
+	// Blocks is parsed after Template so its {{define}} blocks replace the {{block}} blocks of Template
+	Blocks string
 	// Data will be passed to the template execution.
 	Data interface{}
+	// DryRun compares the rendered file with the file on disk instead of writing it, nil writes the file
+	DryRun *DryRun
+	// Naming is the naming strategy of the typeName and plural functions of the template
+	Naming naming.Strategy
 }
 
-func init() { // nolint:gochecknoinits
-	strcase.ConfigureAcronym("QR", "qr")
-	strcase.ConfigureAcronym("KVK", "kvk")
-	strcase.ConfigureAcronym("URL", "url")
-	strcase.ConfigureAcronym("PDF", "pdf")
-	strcase.ConfigureAcronym("FTP", "ftp")
-	strcase.ConfigureAcronym("FCM", "fcm")
+// WriteTemplateFile renders the template and atomically replaces fileName with the result, the file is left untouched
+// when the template could not be rendered
+func WriteTemplateFile(fileName string, cfg Options) error {
+	content, err := RenderTemplateFile(fileName, cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.DryRun != nil {
+		return cfg.DryRun.Compare(fileName, content)
+	}
+	return WriteFile(fileName, content)
 }
 
-func WriteTemplateFile(fileName string, cfg Options) error {
-	content, contentError := GetTemplateContent(cfg)
-	importFixedContent, importsError := imports.Process(fileName, []byte(content), nil)
+// RenderTemplateFile renders the template like WriteTemplateFile would write it to fileName
+func RenderTemplateFile(fileName string, cfg Options) ([]byte, error) {
+	content, err := GetTemplateContent(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not render %v: %w", fileName, err)
+	}
+	importFixedContent, err := imports.Process(fileName, []byte(content), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not fix imports of %v: %w%v", fileName, err, sourceContext([]byte(content), err))
+	}
 
 	fSet := token.NewFileSet()
-	node, err := parser.ParseFile(fSet, "src.go", string(importFixedContent), 0)
+	node, err := parser.ParseFile(fSet, "src.go", importFixedContent, 0)
 	if err != nil {
-		log.Error().Err(err).Msg("could not parse golang file")
+		return nil, fmt.Errorf("could not parse %v: %w%v", fileName, err, sourceContext(importFixedContent, err))
 	}
 
-	ast.Inspect(node, func(n ast.Node) bool {
-		fn, ok := n.(*ast.FuncDecl)
-		if ok && isFunctionOverriddenByUser(fn.Name.Name, cfg.UserDefinedFunctions) {
-			fn.Name.Name = "original" + fn.Name.Name
-		}
-		return true
-	})
-
-	// write new ast to file
-	f, writeError := os.Create(fileName)
-	defer func() {
-		if err := f.Close(); err != nil {
-			log.Error().Err(err).Str("fileName", fileName).Msg("could not close file")
-		}
-	}()
+	if err := renameUserDefinedFunctions(fSet, node, cfg.UserDefinedFunctions); err != nil {
+		return nil, fmt.Errorf("could not override %v: %w", fileName, err)
+	}
+
+	var printed bytes.Buffer
+	if err := printer.Fprint(&printed, fSet, node); err != nil {
+		return nil, fmt.Errorf("could not print %v: %w", fileName, err)
+	}
+	return printed.Bytes(), nil
+}
 
-	if err := printer.Fprint(f, fSet, node); err != nil {
-		return fmt.Errorf("errors while printing template to %v  %v", fileName, err)
+// WriteFile writes content to a temporary file next to fileName and renames it so fileName is never half written,
+// the directory of fileName is created when it does not exist
+func WriteFile(fileName string, content []byte) error {
+	staged, err := StageFile(fileName, content)
+	if err != nil {
+		return err
 	}
+	defer staged.Discard()
+	return staged.Commit()
+}
 
-	if contentError != nil || writeError != nil || importsError != nil {
-		// write fallback to file with content that could not be formatted
-		if err := os.WriteFile(fileName, []byte(content), 0o644); err != nil {
-			return fmt.Errorf("errors while writing template to %v  %v", fileName, err)
-		}
-		return fmt.Errorf(
-			"errors while writing template to %v writeError: %v, contentError: %v, importError: %v",
-			fileName, writeError, contentError, importsError)
+// StagedFile is the content of a file which is written to a temporary file next to its destination but does not
+// replace the destination yet, so several files can be staged before any of them is replaced
+type StagedFile struct {
+	fileName string
+	tmpName  string
+}
+
+// StageFile writes content to a temporary file next to fileName, Commit replaces fileName with it and Discard removes
+// it. The directory of fileName is created when it does not exist.
+func StageFile(fileName string, content []byte) (*StagedFile, error) {
+	if err := os.MkdirAll(filepath.Dir(fileName), 0o755); err != nil {
+		return nil, fmt.Errorf("could not create directory for %v: %w", fileName, err)
 	}
+	tmp, err := os.CreateTemp(filepath.Dir(fileName), "."+filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("could not create temporary file for %v: %w", fileName, err)
+	}
+	staged := &StagedFile{fileName: fileName, tmpName: tmp.Name()}
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		staged.Discard()
+		return nil, fmt.Errorf("could not write %v: %w", fileName, err)
+	}
+	if err := tmp.Close(); err != nil {
+		staged.Discard()
+		return nil, fmt.Errorf("could not write %v: %w", fileName, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil { //nolint:gosec
+		staged.Discard()
+		return nil, fmt.Errorf("could not write %v: %w", fileName, err)
+	}
+	return staged, nil
+}
 
+// FileName returns the destination of the staged file
+func (f *StagedFile) FileName() string {
+	return f.fileName
+}
+
+// Commit replaces the destination with the staged file
+func (f *StagedFile) Commit() error {
+	if err := os.Rename(f.tmpName, f.fileName); err != nil {
+		return fmt.Errorf("could not write %v: %w", f.fileName, err)
+	}
 	return nil
 }
 
+// Discard removes the staged file, it is a no-op after Commit succeeded
+func (f *StagedFile) Discard() {
+	_ = os.Remove(f.tmpName)
+}
+
+// GetTemplateContent renders and formats the template, errors point to the line of the template or of the generated
+// code which could not be formatted. The unformatted content is returned when formatting failed.
 func GetTemplateContent(cfg Options) (string, error) {
-	tpl, err := template.New("").Funcs(template.FuncMap{
+	tpl, err := template.New(cfg.Name).Funcs(template.FuncMap{
 		"go":         gqlgenTemplates.ToGo,
 		"lcFirst":    gqlgenTemplates.LcFirst,
 		"ucFirst":    gqlgenTemplates.UcFirst,
 		"trimSuffix": strings.TrimSuffix,
+		"hasPrefix":  strings.HasPrefix,
+		"dict":       dict,
+		// typeName and plural are the GraphQL names of the naming strategy e.g. for the relationship of a field
+		"typeName": cfg.Naming.TypeName,
+		"plural":   cfg.Naming.Plural,
 	}).Parse(cfg.Template)
 	if err != nil {
-		return "", fmt.Errorf("parse: %v", err)
+		return "", fmt.Errorf("parse: %w", err)
+	}
+	if cfg.Blocks != "" {
+		if _, err := tpl.Parse(cfg.Blocks); err != nil {
+			return "", fmt.Errorf("parse blocks: %w", err)
+		}
 	}
 
 	var content bytes.Buffer
 	err = tpl.Execute(&content, cfg.Data)
 	if err != nil {
-		return "", fmt.Errorf("execute: %v", err)
+		return "", fmt.Errorf("execute: %w", err)
 	}
 
 	contentBytes := content.Bytes()
 	formattedContent, err := format.Source(contentBytes)
 	if err != nil {
-		return string(contentBytes), fmt.Errorf("formatting: %v", err)
+		return string(contentBytes), fmt.Errorf("formatting: %w%v", err, sourceContext(contentBytes, err))
 	}
 
 	return string(formattedContent), nil
 }
 
-func isFunctionOverriddenByUser(functionName string, userDefinedFunctions []string) bool {
-	for _, userDefinedFunction := range userDefinedFunctions {
-		if userDefinedFunction == functionName {
-			return true
+// sourceContext returns the generated lines around the position of a go/scanner error and the function they are part
+// of so it is clear which model or field was rendered wrong
+func sourceContext(src []byte, err error) string {
+	var errorList scanner.ErrorList
+	if !errors.As(err, &errorList) || len(errorList) == 0 {
+		return ""
+	}
+	errorLine := errorList[0].Pos.Line
+	lines := strings.Split(string(src), "\n")
+	if errorLine < 1 || errorLine > len(lines) {
+		return ""
+	}
+
+	var b strings.Builder
+	for i := errorLine - 1; i >= 0; i-- {
+		if strings.HasPrefix(lines[i], "func ") {
+			b.WriteString("\nin " + strings.TrimSuffix(strings.TrimSpace(lines[i]), "{"))
+			break
+		}
+	}
+	from := max(errorLine-contextLines, 1)
+	to := min(errorLine+contextLines, len(lines))
+	for line := from; line <= to; line++ {
+		marker := "  "
+		if line == errorLine {
+			marker = "> "
+		}
+		fmt.Fprintf(&b, "\n%v%4d | %v", marker, line, lines[line-1])
+	}
+	return b.String()
+}
+
+const contextLines = 3
+
+// dict creates a map of key value pairs so a {{block}} can receive more than one value
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict needs key value pairs")
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// renameUserDefinedFunctions renames generated functions which are defined by the user to original{{Name}} so the
+// user defined function is used and the generated one is still available
+func renameUserDefinedFunctions(fSet *token.FileSet, node *ast.File, userDefinedFunctions []customization.Function) error {
+	if len(userDefinedFunctions) == 0 {
+		return nil
+	}
+	userDefined := make(map[string]customization.Function, len(userDefinedFunctions))
+	for _, fn := range userDefinedFunctions {
+		userDefined[fn.Key()] = fn
+	}
+
+	imports := customization.FileImports(node)
+	var errs []error
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		override, ok := userDefined[customization.FunctionKey(customization.ReceiverTypeName(fn), fn.Name.Name)]
+		if !ok {
+			continue
+		}
+		if err := override.CheckSignature(fSet, fn, imports); err != nil {
+			errs = append(errs, err)
+			continue
 		}
+		fn.Name.Name = "original" + fn.Name.Name
 	}
-	return false
+	return errors.Join(errs...)
 }
 
 func ToGo(name string) string {
@@ -0,0 +1,194 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/customization"
+	"github.com/web-ridge/gqlgen-sqlboiler/v3/naming"
+)
+
+func TestWriteTemplateFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		data     interface{}
+		// errs are the parts of the error message, the file is not written when there is an error
+		errs []string
+	}{
+		{
+			name:     "valid",
+			template: "package a\n\nfunc {{ .Name }}() {}\n",
+			data:     map[string]string{"Name": "UserToGraphQL"},
+		},
+		{
+			name:     "execute error",
+			template: "package a\n\n{{ .Name.Missing }}\n",
+			data:     map[string]string{"Name": "User"},
+			errs:     []string{"generated_a.gotpl:3", "Missing"},
+		},
+		{
+			name:     "format error",
+			template: "package a\n\nfunc {{ .Name }}() {\n\treturn {{ .Field }}\n}\n",
+			data:     map[string]string{"Name": "UserToGraphQL", "Field": "m.)"},
+			errs:     []string{"formatting", "in func UserToGraphQL()", ">    4 | \treturn m.)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "generated_a.go")
+			const existing = "package a\n"
+			if err := os.WriteFile(fileName, []byte(existing), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := WriteTemplateFile(fileName, Options{Name: "generated_a.gotpl", Template: tt.template, Data: tt.data})
+
+			content, readErr := os.ReadFile(fileName)
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+			if tt.errs == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(content) == existing {
+					t.Error("expected the file to be written")
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, part := range tt.errs {
+				if !strings.Contains(err.Error(), part) {
+					t.Errorf("error does not contain %q:\n%v", part, err)
+				}
+			}
+			if string(content) != existing {
+				t.Errorf("file should be left intact on errors, got:\n%v", content)
+			}
+		})
+	}
+}
+
+func TestWriteFileCreatesDirectory(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "resolvers", "all_generated_resolvers.go")
+	if err := WriteFile(fileName, []byte("package resolvers\n")); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(fileName); err != nil || string(content) != "package resolvers\n" {
+		t.Errorf("expected the file to be written, got %q: %v", content, err)
+	}
+}
+
+func TestGetTemplateContentNaming(t *testing.T) {
+	content, err := GetTemplateContent(Options{
+		Template: `package a
+
+var {{ typeName "User" }} = "{{ plural "Huis" }}"
+`,
+		Naming: naming.Strategy{TypeSuffix: "Node", Plurals: map[string]string{"huis": "huizen"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, `var UserNode = "Huizen"`) {
+		t.Errorf("expected the names of the naming strategy, got %v", content)
+	}
+}
+
+func TestRenderTemplateFileUserDefinedFunctions(t *testing.T) {
+	const template = `package a
+
+type converter struct{}
+
+func UserToGraphQL(id string) string { return id }
+
+func (c *converter) PostToGraphQL(id string) string { return id }
+`
+	tests := []struct {
+		name                 string
+		userDefinedFunctions []customization.Function
+		renamed              []string
+		err                  string
+	}{
+		{
+			name: "function",
+			userDefinedFunctions: []customization.Function{
+				{
+					Name:                "UserToGraphQL",
+					Signature:           "func(string) string",
+					NormalizedSignature: "func(string) string",
+				},
+			},
+			renamed: []string{"func originalUserToGraphQL(", "func (c *converter) PostToGraphQL("},
+		},
+		{
+			name: "method",
+			userDefinedFunctions: []customization.Function{
+				{
+					Receiver:            "converter",
+					Name:                "PostToGraphQL",
+					Signature:           "func(string) string",
+					NormalizedSignature: "func(string) string",
+				},
+			},
+			renamed: []string{"func UserToGraphQL(", "func (c *converter) originalPostToGraphQL("},
+		},
+		{
+			name: "method with the name of a function",
+			userDefinedFunctions: []customization.Function{
+				{
+					Receiver:            "other",
+					Name:                "UserToGraphQL",
+					Signature:           "func(string) string",
+					NormalizedSignature: "func(string) string",
+				},
+				{
+					Name:                "PostToGraphQL",
+					Signature:           "func(string) string",
+					NormalizedSignature: "func(string) string",
+				},
+			},
+			renamed: []string{"func UserToGraphQL(", "func (c *converter) PostToGraphQL("},
+		},
+		{
+			name: "signature mismatch",
+			userDefinedFunctions: []customization.Function{
+				{
+					Name:                "UserToGraphQL",
+					Signature:           "func(int) string",
+					NormalizedSignature: "func(int) string",
+					Position:            "convert_override_user.go:3",
+				},
+			},
+			err: "UserToGraphQL in convert_override_user.go:3 has signature func(int) string but the generated " +
+				"UserToGraphQL has func(string) string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := RenderTemplateFile(filepath.Join(t.TempDir(), "generated_a.go"), Options{
+				Template:             template,
+				UserDefinedFunctions: tt.userDefinedFunctions,
+			})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, part := range tt.renamed {
+				if !strings.Contains(string(content), part) {
+					t.Errorf("expected %q in:\n%s", part, content)
+				}
+			}
+		})
+	}
+}
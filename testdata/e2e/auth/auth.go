@@ -0,0 +1,15 @@
+// Package auth is the authorization scope of the end-to-end tests, posts are scoped to the user of the request
+package auth
+
+import "context"
+
+type contextKey struct{}
+
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, contextKey{}, userID)
+}
+
+func UserIDFromContext(ctx context.Context) uint {
+	userID, _ := ctx.Value(contextKey{}).(uint)
+	return userID
+}
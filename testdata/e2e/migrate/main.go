@@ -0,0 +1,28 @@
+// Command migrate creates the sqlite database sqlboiler generates the models from
+package main
+
+import (
+	"database/sql"
+	"log"
+	"os"
+
+	_ "modernc.org/sqlite"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		log.Fatal("usage: migrate <database> <schema.sql>")
+	}
+	schema, err := os.ReadFile(os.Args[2])
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(string(schema)); err != nil {
+		log.Fatal(err)
+	}
+}
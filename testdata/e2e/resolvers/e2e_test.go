@@ -0,0 +1,463 @@
+package resolvers
+
+import (
+	"database/sql"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	_ "modernc.org/sqlite"
+
+	"example.com/fixture/auth"
+	"example.com/fixture/models/fm"
+)
+
+const userIDHeader = "X-User-ID"
+
+func openDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	// case_sensitive_like makes the strict string filters behave like they do in mysql and postgres
+	dsn := "file:" + filepath.Join(t.TempDir(), "e2e.db") +
+		"?_pragma=foreign_keys(1)&_pragma=case_sensitive_like(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	for _, file := range []string{"../schema.sql", "../seed.sql"} {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(content)); err != nil {
+			t.Fatalf("%v: %v", file, err)
+		}
+	}
+	return db
+}
+
+func newClient(t *testing.T) (*client.Client, *sql.DB) {
+	t.Helper()
+	db := openDatabase(t)
+	srv := handler.New(fm.NewExecutableSchema(fm.Config{Resolvers: NewResolver(db)}))
+	srv.AddTransport(transport.POST{})
+	srv.SetErrorPresenter(ErrorPresenter)
+
+	withUser := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userID, err := strconv.ParseUint(r.Header.Get(userIDHeader), 10, 64); err == nil {
+			r = r.WithContext(auth.WithUserID(r.Context(), uint(userID)))
+		}
+		srv.ServeHTTP(w, r)
+	})
+	return client.New(withUser), db
+}
+
+func asUser(userID int) client.Option {
+	return client.AddHeader(userIDHeader, strconv.Itoa(userID))
+}
+
+type pageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+type node struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type connection struct {
+	Edges []struct {
+		Cursor string `json:"cursor"`
+		Node   node   `json:"node"`
+	} `json:"edges"`
+	PageInfo pageInfo `json:"pageInfo"`
+}
+
+func (c connection) names() []string {
+	names := make([]string, 0, len(c.Edges))
+	for _, edge := range c.Edges {
+		names = append(names, edge.Node.Name)
+	}
+	return names
+}
+
+const usersQuery = `query($first: Int!, $after: String, $ordering: [UserOrdering!], $filter: UserFilter) {
+	users(first: $first, after: $after, ordering: $ordering, filter: $filter) {
+		edges { cursor node { id name } }
+		pageInfo { hasNextPage hasPreviousPage startCursor endCursor }
+	}
+}`
+
+// posts are generated with backward pagination, see TestEndToEnd of the generator
+const postsQuery = `query($last: Int!, $before: String, $filter: PostFilter) {
+	posts(last: $last, before: $before, ordering: [{sort: ID, direction: ASC}], filter: $filter) {
+		edges { cursor node { id name: title } }
+		pageInfo { hasNextPage hasPreviousPage startCursor endCursor }
+	}
+}`
+
+func queryUsers(t *testing.T, c *client.Client, options ...client.Option) connection {
+	t.Helper()
+	var resp struct {
+		Users connection `json:"users"`
+	}
+	options = append([]client.Option{
+		client.Var("first", 10),
+		client.Var("ordering", []map[string]interface{}{{"sort": "ID", "direction": "ASC"}}),
+	}, options...)
+	if err := c.Post(usersQuery, &resp, options...); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Users
+}
+
+func queryPosts(t *testing.T, c *client.Client, options ...client.Option) connection {
+	t.Helper()
+	var resp struct {
+		Posts connection `json:"posts"`
+	}
+	options = append([]client.Option{client.Var("last", 10)}, options...)
+	if err := c.Post(postsQuery, &resp, options...); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Posts
+}
+
+func where(where map[string]interface{}) client.Option {
+	return client.Var("filter", map[string]interface{}{"where": where})
+}
+
+func assertNames(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(want) == 0 {
+		want = []string{}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestStringFilter(t *testing.T) {
+	c, _ := newClient(t)
+	tests := []struct {
+		column string
+		filter map[string]interface{}
+		want   []string
+	}{
+		{"email", map[string]interface{}{"isNullOrEmpty": true}, []string{"bob", "Carol"}},
+		{"email", map[string]interface{}{"isEmpty": true}, []string{"Carol"}},
+		{"email", map[string]interface{}{"isNull": true}, []string{"bob"}},
+		{"email", map[string]interface{}{"notNullOrEmpty": true}, []string{"Alice"}},
+		{"email", map[string]interface{}{"notEmpty": true}, []string{"Alice"}},
+		{"email", map[string]interface{}{"notNull": true}, []string{"Alice", "Carol"}},
+		{"name", map[string]interface{}{"equalTo": "Alice"}, []string{"Alice"}},
+		{"name", map[string]interface{}{"notEqualTo": "Alice"}, []string{"bob", "Carol"}},
+		{"name", map[string]interface{}{"in": []string{"Alice", "Carol"}}, []string{"Alice", "Carol"}},
+		{"name", map[string]interface{}{"notIn": []string{"Alice"}}, []string{"bob", "Carol"}},
+		{"name", map[string]interface{}{"startWith": "a"}, []string{"Alice"}},
+		{"name", map[string]interface{}{"notStartWith": "a"}, []string{"bob", "Carol"}},
+		{"name", map[string]interface{}{"endWith": "OL"}, []string{"Carol"}},
+		{"name", map[string]interface{}{"notEndWith": "ol"}, []string{"Alice", "bob"}},
+		{"name", map[string]interface{}{"contain": "LI"}, []string{"Alice"}},
+		{"name", map[string]interface{}{"notContain": "li"}, []string{"bob", "Carol"}},
+		{"name", map[string]interface{}{"startWithStrict": "b"}, []string{"bob"}},
+		{"name", map[string]interface{}{"startWithStrict": "B"}, nil},
+		{"name", map[string]interface{}{"notStartWithStrict": "b"}, []string{"Alice", "Carol"}},
+		{"name", map[string]interface{}{"endWithStrict": "ce"}, []string{"Alice"}},
+		{"name", map[string]interface{}{"endWithStrict": "CE"}, nil},
+		{"name", map[string]interface{}{"notEndWithStrict": "ce"}, []string{"bob", "Carol"}},
+		{"name", map[string]interface{}{"containStrict": "aro"}, []string{"Carol"}},
+		{"name", map[string]interface{}{"containStrict": "ARO"}, nil},
+		{"name", map[string]interface{}{"notContainStrict": "aro"}, []string{"Alice", "bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column+"/"+firstKey(tt.filter), func(t *testing.T) {
+			users := queryUsers(t, c, where(map[string]interface{}{tt.column: tt.filter}))
+			assertNames(t, users.names(), tt.want...)
+		})
+	}
+}
+
+func TestIntFilter(t *testing.T) {
+	c, _ := newClient(t)
+	tests := []struct {
+		filter map[string]interface{}
+		want   []string
+	}{
+		{map[string]interface{}{"isNullOrZero": true}, []string{"Carol"}},
+		{map[string]interface{}{"isNull": true}, []string{"Carol"}},
+		{map[string]interface{}{"notNullOrZero": true}, []string{"Alice", "bob"}},
+		{map[string]interface{}{"notNull": true}, []string{"Alice", "bob"}},
+		{map[string]interface{}{"equalTo": 30}, []string{"Alice"}},
+		{map[string]interface{}{"notEqualTo": 30}, []string{"bob"}},
+		{map[string]interface{}{"lessThan": 30}, []string{"bob"}},
+		{map[string]interface{}{"lessThanOrEqualTo": 30}, []string{"Alice", "bob"}},
+		{map[string]interface{}{"moreThan": 25}, []string{"Alice"}},
+		{map[string]interface{}{"moreThanOrEqualTo": 25}, []string{"Alice", "bob"}},
+		{map[string]interface{}{"in": []int{25}}, []string{"bob"}},
+		{map[string]interface{}{"notIn": []int{25}}, []string{"Alice"}},
+	}
+	for _, tt := range tests {
+		t.Run(firstKey(tt.filter), func(t *testing.T) {
+			users := queryUsers(t, c, where(map[string]interface{}{"age": tt.filter}))
+			assertNames(t, users.names(), tt.want...)
+		})
+	}
+}
+
+func TestRelationFilter(t *testing.T) {
+	c, _ := newClient(t)
+
+	users := queryUsers(t, c, where(map[string]interface{}{
+		"organization": map[string]interface{}{"name": map[string]interface{}{"equalTo": "Globex"}},
+	}))
+	assertNames(t, users.names(), "Carol")
+
+	users = queryUsers(t, c, where(map[string]interface{}{
+		"posts": map[string]interface{}{"title": map[string]interface{}{"contain": "hello"}},
+	}))
+	assertNames(t, users.names(), "Alice")
+
+	posts := queryPosts(t, c, asUser(1), where(map[string]interface{}{
+		"user": map[string]interface{}{
+			"organization": map[string]interface{}{"name": map[string]interface{}{"equalTo": "Acme"}},
+		},
+	}))
+	assertNames(t, posts.names(), "Hello World", "hello again", "Third")
+}
+
+func TestForwardPagination(t *testing.T) {
+	c, _ := newClient(t)
+	ordering := client.Var("ordering", []map[string]interface{}{{"sort": "NAME", "direction": "DESC"}})
+
+	page := queryUsers(t, c, ordering, client.Var("first", 2))
+	assertNames(t, page.names(), "bob", "Carol")
+	if !page.PageInfo.HasNextPage || page.PageInfo.EndCursor == nil {
+		t.Fatalf("expected a next page, got %+v", page.PageInfo)
+	}
+
+	page = queryUsers(t, c, ordering, client.Var("first", 2), client.Var("after", *page.PageInfo.EndCursor))
+	assertNames(t, page.names(), "Alice")
+	if page.PageInfo.HasNextPage {
+		t.Errorf("expected no next page, got %+v", page.PageInfo)
+	}
+}
+
+func TestBackwardPagination(t *testing.T) {
+	c, _ := newClient(t)
+
+	page := queryPosts(t, c, asUser(1), client.Var("last", 2))
+	assertNames(t, page.names(), "hello again", "Third")
+	if !page.PageInfo.HasPreviousPage || page.PageInfo.StartCursor == nil {
+		t.Fatalf("expected a previous page, got %+v", page.PageInfo)
+	}
+
+	page = queryPosts(t, c, asUser(1), client.Var("last", 2), client.Var("before", *page.PageInfo.StartCursor))
+	assertNames(t, page.names(), "Hello World")
+	if page.PageInfo.HasPreviousPage {
+		t.Errorf("expected no previous page, got %+v", page.PageInfo)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	c, db := newClient(t)
+	withDeleted := where(map[string]interface{}{"withDeleted": true})
+
+	assertNames(t, queryUsers(t, c).names(), "Alice", "bob", "Carol")
+	assertNames(t, queryUsers(t, c, withDeleted).names(), "Alice", "bob", "Carol", "Dave")
+
+	bob := queryUsers(t, c).Edges[1].Node
+	var resp struct {
+		DeleteUser struct {
+			ID string `json:"id"`
+		} `json:"deleteUser"`
+	}
+	if err := c.Post(`mutation($id: ID!) { deleteUser(id: $id) { id } }`, &resp, client.Var("id", bob.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNames(t, queryUsers(t, c).names(), "Alice", "Carol")
+	assertNames(t, queryUsers(t, c, withDeleted).names(), "Alice", "bob", "Carol", "Dave")
+
+	var deletedAt sql.NullTime
+	if err := db.QueryRow("SELECT deleted_at FROM users WHERE name = 'bob'").Scan(&deletedAt); err != nil {
+		t.Fatal(err)
+	}
+	if !deletedAt.Valid {
+		t.Error("expected bob to be soft deleted")
+	}
+}
+
+func TestScopedMutations(t *testing.T) {
+	c, db := newClient(t)
+	carolsPost := queryPosts(t, c, asUser(3)).Edges[0].Node
+	alicesPost := queryPosts(t, c, asUser(1)).Edges[0].Node
+
+	t.Run("list", func(t *testing.T) {
+		assertNames(t, queryPosts(t, c, asUser(3)).names(), "Carols post")
+	})
+
+	t.Run("create", func(t *testing.T) {
+		var resp struct {
+			CreatePost struct {
+				Post node `json:"post"`
+			} `json:"createPost"`
+		}
+		err := c.Post(`mutation { createPost(input: {title: "Scoped", createdAt: 0}) { post { id name: title } } }`,
+			&resp, asUser(3))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var userID int
+		if err := db.QueryRow("SELECT user_id FROM posts WHERE title = 'Scoped'").Scan(&userID); err != nil {
+			t.Fatal(err)
+		}
+		if userID != 3 {
+			t.Errorf("created post belongs to user %v, want 3", userID)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		const mutation = `mutation($id: ID!) { updatePost(id: $id, input: {title: "Updated"}) { post { id } } }`
+		var resp map[string]interface{}
+		if err := c.Post(mutation, &resp, asUser(1), client.Var("id", carolsPost.ID)); err == nil {
+			t.Error("expected an error when updating a post of another user")
+		}
+		assertTitle(t, db, 3, "Carols post")
+
+		if err := c.Post(mutation, &resp, asUser(1), client.Var("id", alicesPost.ID)); err != nil {
+			t.Fatal(err)
+		}
+		assertTitle(t, db, 1, "Updated")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		const mutation = `mutation($id: ID!) { deletePost(id: $id) { id } }`
+		var resp map[string]interface{}
+		_ = c.Post(mutation, &resp, asUser(1), client.Var("id", carolsPost.ID))
+		assertTitle(t, db, 3, "Carols post")
+
+		if err := c.Post(mutation, &resp, asUser(1), client.Var("id", alicesPost.ID)); err != nil {
+			t.Fatal(err)
+		}
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM posts WHERE id = 1").Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Error("expected the post of the user to be deleted")
+		}
+	})
+}
+
+func assertTitle(t *testing.T, db *sql.DB, postID int, want string) {
+	t.Helper()
+	var title string
+	if err := db.QueryRow("SELECT title FROM posts WHERE id = ?", postID).Scan(&title); err != nil {
+		t.Fatal(err)
+	}
+	if title != want {
+		t.Errorf("post %v has title %q, want %q", postID, title, want)
+	}
+}
+
+func firstKey(m map[string]interface{}) string {
+	for key := range m {
+		return key
+	}
+	return ""
+}
+
+func TestNode(t *testing.T) {
+	c, _ := newClient(t)
+	alice := queryUsers(t, c).Edges[0].Node
+	carolsPost := queryPosts(t, c, asUser(3)).Edges[0].Node
+
+	const query = `query($id: ID!, $ids: [ID!]!) {
+		node(id: $id) { id ... on User { name } }
+		nodes(ids: $ids) { id ... on Post { name: title } }
+	}`
+	var resp struct {
+		Node  node    `json:"node"`
+		Nodes []*node `json:"nodes"`
+	}
+	err := c.Post(query, &resp, asUser(3),
+		client.Var("id", alice.ID),
+		client.Var("ids", []string{carolsPost.ID, "invalid", alice.ID}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Node != alice {
+		t.Errorf("node returned %v, want %v", resp.Node, alice)
+	}
+	if len(resp.Nodes) != 3 || resp.Nodes[0] == nil || *resp.Nodes[0] != carolsPost || resp.Nodes[1] != nil {
+		t.Errorf("nodes returned %v", resp.Nodes)
+	}
+
+	// the post of carol is not in the scope of alice
+	err = c.Post(query, &resp, asUser(1), client.Var("id", carolsPost.ID), client.Var("ids", []string{}))
+	if err == nil {
+		t.Error("expected an error when fetching a post of another user")
+	}
+}
+
+func TestNodePreloads(t *testing.T) {
+	c, _ := newClient(t)
+	alice := queryUsers(t, c).Edges[0].Node
+	alicesPost := queryPosts(t, c, asUser(1)).Edges[0].Node
+
+	// the relations are only selected in the fragments so they have to be preloaded for the type of the node
+	const query = `query($id: ID!, $ids: [ID!]!) {
+		node(id: $id) { id ... on User { organization { name } } }
+		nodes(ids: $ids) { id ... on Post { user { name } } }
+	}`
+	var resp struct {
+		Node struct {
+			Organization *node `json:"organization"`
+		} `json:"node"`
+		Nodes []*struct {
+			User *node `json:"user"`
+		} `json:"nodes"`
+	}
+	err := c.Post(query, &resp, asUser(1), client.Var("id", alice.ID), client.Var("ids", []string{alicesPost.ID}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Node.Organization == nil || resp.Node.Organization.Name != "Acme" {
+		t.Errorf("node returned organization %v, want Acme", resp.Node.Organization)
+	}
+	if len(resp.Nodes) != 1 || resp.Nodes[0] == nil || resp.Nodes[0].User == nil || resp.Nodes[0].User.Name != "Alice" {
+		t.Errorf("nodes returned %v, want a post of Alice", resp.Nodes)
+	}
+}
+
+func TestWrongIDType(t *testing.T) {
+	c, _ := newClient(t)
+	alice := queryUsers(t, c).Edges[0].Node
+	alicesPost := queryPosts(t, c, asUser(1)).Edges[0].Node
+
+	var resp map[string]interface{}
+	err := c.Post(`mutation($id: ID!) { updatePost(id: $id, input: {title: "Wrong"}) { post { id } } }`,
+		&resp, asUser(1), client.Var("id", alice.ID))
+	if err == nil || !strings.Contains(err.Error(), "expected an id of posts but received an id of users") {
+		t.Errorf("expected a wrong id type error, got %v", err)
+	}
+
+	err = c.Post(usersQuery, &resp, client.Var("first", 10),
+		where(map[string]interface{}{"id": map[string]interface{}{"equalTo": alicesPost.ID}}))
+	if err == nil || !strings.Contains(err.Error(), "id: wrong id type") {
+		t.Errorf("expected a wrong id type error, got %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package resolvers
+
+import (
+	"database/sql"
+	"log/slog"
+)
+
+type Resolver struct {
+	db     *sql.DB
+	logger ResolverLogger
+}
+
+func NewResolver(db *sql.DB) *Resolver {
+	return &Resolver{
+		db:     db,
+		logger: slog.Default(),
+	}
+}
@@ -0,0 +1,23 @@
+CREATE TABLE organizations (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       TEXT     NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE users (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    name            TEXT     NOT NULL,
+    email           TEXT,
+    age             INTEGER,
+    organization_id INTEGER REFERENCES organizations (id),
+    created_at      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at      DATETIME
+);
+
+CREATE TABLE posts (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    title      TEXT     NOT NULL,
+    body       TEXT,
+    user_id    INTEGER  NOT NULL REFERENCES users (id),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,15 @@
+INSERT INTO organizations (id, name) VALUES
+    (1, 'Acme'),
+    (2, 'Globex');
+
+INSERT INTO users (id, name, email, age, organization_id, deleted_at) VALUES
+    (1, 'Alice', 'alice@example.com', 30, 1, NULL),
+    (2, 'bob', NULL, 25, 1, NULL),
+    (3, 'Carol', '', NULL, 2, NULL),
+    (4, 'Dave', 'dave@example.com', 40, NULL, '2020-01-01 00:00:00');
+
+INSERT INTO posts (id, title, body, user_id) VALUES
+    (1, 'Hello World', NULL, 1),
+    (2, 'hello again', 'second post', 1),
+    (3, 'Carols post', NULL, 3),
+    (4, 'Third', NULL, 1);
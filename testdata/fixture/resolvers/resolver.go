@@ -0,0 +1,17 @@
+package resolvers
+
+import (
+	"database/sql"
+
+	"example.com/fixture/helpers"
+)
+
+type Resolver struct {
+	db              *sql.DB
+	logger          ResolverLogger
+	instrumentation helpers.Instrumentation
+}
+
+func NewResolver(db *sql.DB, logger ResolverLogger, instrumentation helpers.Instrumentation) *Resolver {
+	return &Resolver{db: db, logger: logger, instrumentation: instrumentation}
+}